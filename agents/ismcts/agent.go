@@ -10,7 +10,12 @@
 // them for longer achieves better results.
 //
 // This package implements a parallelization technique on top of ISMCTS[2]
-// that will start as many goroutines as there are cores on the machine.
+// that will start as many goroutines as there are cores on the machine
+// (or as many as given with WithWorkers).
+//
+// The agent can also ponder: search in the background while the opponent
+// is thinking, and continue that search once it is asked for its move
+// (see Agent.StartPondering).
 //
 // [1] Peter I. Cowling, Edward Powley and Daniel Whitehouse, “Information Set Monte Carlo Tree Search” http://orangehelicopter.com/academic/papers/tciaig_ismcts.pdf
 //
@@ -18,113 +23,79 @@
 package ismcts
 
 import (
+	"context"
 	"math"
 	"math/rand"
 	"runtime"
+	"sync"
 	"time"
 
 	santase "github.com/nvlbg/santase-ai"
 )
 
+// action is a choice of a player in the search tree. It stands for
+// exactly one move (see toMove), so switching the trump card and
+// announcing a marriage are choices the search explores like the card
+// to play. Declaring that the player has 66 points is an action on its
+// own, possibly combined with announcing the marriage of card.
 type action struct {
-	card      santase.Card
-	closeGame bool
+	card            santase.Card
+	switchTrumpCard bool
+	announce        bool
+	closeGame       bool
+	declare         bool
+}
+
+// toMove returns the move the action stands for.
+func (a action) toMove() santase.Move {
+	return santase.Move{
+		Card:            a.card,
+		IsAnnouncement:  a.announce,
+		SwitchTrumpCard: a.switchTrumpCard,
+		CloseGame:       a.closeGame,
+		Declare:         a.declare,
+	}
 }
 
+// node is a node of the search tree. The score is the sum of the
+// game points of the simulations through the node from the point of
+// view of the AI (positive when the AI won), wins is the number of
+// simulations the AI won and gamePoints is the sum of the game points
+// the AI won.
 type node struct {
 	parent       *node
 	children     map[action]*node
 	availability int
 	visits       int
 	score        int
+	wins         int
+	gamePoints   int
 }
 
 func (n *node) isTerminal() bool {
 	return len(n.children) == 0
 }
 
-func (n *node) isExpanded(game *game) bool {
-	hand := game.getHand()
-	canCloseGame := game.canClose()
-
-	for card := range hand {
-		isCardLegal := game.isCardLegal(card)
-		if !isCardLegal {
-			continue
-		}
-
-		// check if the move of playing this card is explored
-		child := n.children[action{card: card}]
+// isExpanded returns if all the legal actions at the
+// node have children that have been visited.
+func (n *node) isExpanded(actions []action) bool {
+	for _, a := range actions {
+		child := n.children[a]
 		if child == nil || child.visits == 0 {
 			return false
 		}
-
-		// check if closing the game and playing the card is explored (if possible)
-		if canCloseGame {
-			child = n.children[action{card: card, closeGame: true}]
-			if child == nil || child.visits == 0 {
-				return false
-			}
-		}
-	}
-
-	if game.cardPlayed == nil && !game.isClosed && len(game.stack) > 1 && len(game.stack) < 11 {
-		// check if switching is explored (if possible)
-		nineTrump := santase.NewCard(santase.Nine, game.trump)
-		child := n.children[action{card: *game.trumpCard}]
-		if hand.HasCard(nineTrump) && (child == nil || child.visits == 0) {
-			return false
-		}
-
-		// check if switching and closing the game is explored (if possible)
-		child = n.children[action{card: *game.trumpCard, closeGame: true}]
-		if hand.HasCard(nineTrump) && (child == nil || child.visits == 0) {
-			return false
-		}
 	}
 
 	return true
 }
 
-func (n *node) expandRandomChild(g *game) *node {
-	hand := g.getHand()
-	canCloseGame := g.canClose()
-
+func (n *node) expandRandomChild(g *game, actions []action) *node {
 	var unexpandedActions []action
-	for card := range hand {
-		isLegal := g.isCardLegal(card)
-		if !isLegal {
-			continue
-		}
-
-		a := action{card: card}
+	for _, a := range actions {
 		child := n.children[a]
 		if child == nil || child.visits == 0 {
 			unexpandedActions = append(unexpandedActions, a)
 		}
-
-		if canCloseGame {
-			a := action{card: card, closeGame: true}
-			child := n.children[a]
-			if child == nil || child.visits == 0 {
-				unexpandedActions = append(unexpandedActions, a)
-			}
-		}
-	}
-
-	if g.cardPlayed == nil && !g.isClosed && len(g.stack) > 1 && len(g.stack) < 11 {
-		nineTrump := santase.NewCard(santase.Nine, g.trump)
-		a := action{card: *g.trumpCard}
-		child := n.children[a]
-		if hand.HasCard(nineTrump) && (child == nil || child.visits == 0) {
-			unexpandedActions = append(unexpandedActions, a)
-		}
-
-		a = action{card: *g.trumpCard, closeGame: true}
-		child = n.children[a]
-		if hand.HasCard(nineTrump) && (child == nil || child.visits == 0) {
-			unexpandedActions = append(unexpandedActions, a)
-		}
 	}
 
 	for _, a := range unexpandedActions {
@@ -138,308 +109,218 @@ func (n *node) expandRandomChild(g *game) *node {
 			}
 		}
 	}
-	action := unexpandedActions[rand.Intn(len(unexpandedActions))]
+	action := unexpandedActions[g.rng.Intn(len(unexpandedActions))]
 	g.simulate(action)
 	n.children[action].visits++
 	return n.children[action]
 }
 
+// game is a determinization of the game searched by the agent: a
+// complete state of the deal in which the cards hidden from the AI
+// are dealt at random.
 type game struct {
-	score          int
-	opponentScore  int
-	hand           santase.Hand
-	opponentHand   santase.Hand
-	trump          santase.Suit
-	stack          []santase.Card
-	trumpCard      *santase.Card
-	cardPlayed     *santase.Card
-	isOpponentMove bool
-	isClosed       bool
+	santase.State
+	rng *rand.Rand
 }
 
-func (g *game) canClose() bool {
-	return g.cardPlayed == nil && !g.isClosed && len(g.stack) > 1 && len(g.stack) < 11
-}
-func (g *game) getHand() santase.Hand {
-	if g.isOpponentMove {
-		return g.opponentHand
+// player returns the player to move.
+func (g *game) player() santase.Player {
+	if g.IsOpponentMove() {
+		return santase.Opponent
 	}
-	return g.hand
+	return santase.AI
 }
 
-func (g *game) isCardLegal(card santase.Card) bool {
-	// you're first to play or the game is not closed
-	if g.cardPlayed == nil || (g.trumpCard != nil && !g.isClosed) {
-		return true
+func (g *game) getScore() int {
+	tally := g.GetTally()
+	if g.IsOpponentMove() {
+		return tally.OpponentScore
 	}
+	return tally.Score
+}
 
-	// playing stronger card of the requested suit
-	if card.Suit == g.cardPlayed.Suit && card.Rank > g.cardPlayed.Rank {
-		return true
+func (g *game) getHand() santase.Hand {
+	if g.IsOpponentMove() {
+		return g.GetOpponentHand()
 	}
+	return g.GetHand()
+}
 
-	hand := g.getHand()
-	if g.cardPlayed.Suit == card.Suit {
-		for c := range hand {
-			if c.Suit == g.cardPlayed.Suit && c.Rank > g.cardPlayed.Rank {
-				// you're holding stronger card of the same suit that you must play
-				return false
-			}
+// considersClosing returns if the search considers closing the game
+// for the player to move. Closing is only considered once the player
+// has collected half of the target score. Failing to win after closing
+// is heavily penalized, and exploring early closes with random playouts
+// makes the search avoid taking the lead.
+func (g *game) considersClosing() bool {
+	return 2*g.getScore() >= g.GetRules().TargetScore
+}
+
+// canSwitch returns if the player to move can switch the trump card.
+func (g *game) canSwitch() bool {
+	trumpCard := g.GetTrumpCard()
+	return trumpCard != nil && g.Validate(santase.Move{Card: *trumpCard, SwitchTrumpCard: true}) == nil
+}
+
+// legalActions returns the actions the player to move can choose from:
+// the legal moves, apart from the closes the search does not consider
+// (see considersClosing) and the declarations that do not win. A
+// declaration with an announcement is made with the queen.
+func (g *game) legalActions() []action {
+	closing := g.considersClosing()
+
+	var actions []action
+	for _, move := range g.LegalMoves() {
+		if move.CloseGame && !closing {
+			continue
 		}
-		// you don't have stronger card of the same suit
-		return true
+		if move.Declare && (move.IsAnnouncement && move.Card.Rank == santase.King || !g.wins(move)) {
+			continue
+		}
+		actions = append(actions, toAction(move))
 	}
+	return actions
+}
 
-	for c := range hand {
-		if c.Suit == g.cardPlayed.Suit {
-			// you're holding card of the requested suit that you must play
-			return false
-		}
+// declarations returns the declarations of the player to move that
+// win the deal.
+func (g *game) declarations() []santase.Move {
+	if g.GetCardPlayed() != nil {
+		return nil
 	}
 
-	if g.cardPlayed.Suit != g.trump && card.Suit == g.trump {
-		// you are forced to play trump card in this case
-		return true
+	var moves []santase.Move
+	if g.wins(santase.Move{Declare: true}) {
+		moves = append(moves, santase.Move{Declare: true})
 	}
 
-	if g.cardPlayed.Suit != g.trump {
-		for c := range hand {
-			if c.Suit == g.trump {
-				// you're holding a trump card that you should play
-				return false
-			}
+	hand := g.getHand()
+	for _, card := range hand.ToSlice() {
+		move := santase.Move{Card: card, IsAnnouncement: true, Declare: true}
+		if card.Rank == santase.Queen && g.wins(move) {
+			moves = append(moves, move)
 		}
 	}
+	return moves
+}
+
+// wins returns if the move is legal and the
+// player to move wins the deal by playing it.
+func (g *game) wins(move santase.Move) bool {
+	next := g.State
+	if next.Apply(move) != nil {
+		return false
+	}
 
-	// your move is valid
-	return true
+	winner, _ := next.GetTally().Result()
+	return winner == g.player()
 }
 
 func (g *game) simulate(a action) {
-	hand := g.getHand()
-
-	if g.cardPlayed == nil {
-		// check if switching is possible
-		if g.trumpCard != nil && !g.isClosed && g.trumpCard.Rank != santase.Nine && len(g.stack) > 1 && len(g.stack) < 11 {
-			nineTrump := santase.NewCard(santase.Nine, g.trump)
-			if a.card != nineTrump && hand.HasCard(nineTrump) {
-				hand.RemoveCard(nineTrump)
-				hand.AddCard(*g.trumpCard)
-				g.trumpCard = &nineTrump
-			}
-		}
+	if err := g.Apply(a.toMove()); err != nil {
+		panic(err.Error())
+	}
+}
 
-		if a.closeGame {
-			g.isClosed = true
-		}
+// randomMove returns the move of the player to move in a playout. The
+// player declares as soon as they win by declaring and otherwise plays
+// a random card. Switching the trump card and announcing a marriage are
+// almost always good, so they are made whenever possible (the search
+// tree explores not making them), and the game is closed with
+// probability 1/7 when closing is considered.
+func (g *game) randomMove() santase.Move {
+	if declarations := g.declarations(); len(declarations) > 0 {
+		return declarations[0]
+	}
 
-		// check if announcing is possible
-		if a.card.Rank == santase.Queen || a.card.Rank == santase.King && len(g.stack) < 11 {
-			var other santase.Card
-			if a.card.Rank == santase.Queen {
-				other = santase.NewCard(santase.King, a.card.Suit)
-			} else {
-				other = santase.NewCard(santase.Queen, a.card.Suit)
-			}
+	var move santase.Move
+	hand := g.getHand()
+	if g.canSwitch() {
+		move.SwitchTrumpCard = true
+		hand.RemoveCard(santase.NewCard(santase.Nine, g.GetTrump()))
+		hand.AddCard(*g.GetTrumpCard())
+	}
 
-			if hand.HasCard(other) {
-				var announcementPoints int
-				if a.card.Suit == g.trump {
-					announcementPoints = 40
-				} else {
-					announcementPoints = 20
-				}
-
-				if g.isOpponentMove {
-					g.opponentScore += announcementPoints
-				} else {
-					g.score += announcementPoints
-				}
-			}
-		}
+	cardPlayed := g.GetCardPlayed()
+	if cardPlayed != nil && (g.GetTrumpCard() == nil || g.IsClosed()) {
+		hand = hand.GetValidResponses(*cardPlayed, g.GetTrump())
+	}
+	move.Card = hand.GetRandomCardFrom(g.rng)
 
-		g.cardPlayed = &a.card
-		hand.RemoveCard(a.card)
-		g.isOpponentMove = !g.isOpponentMove
-	} else {
-		stronger := santase.StrongerCard(g.cardPlayed, &a.card, g.trump)
-		var winnerScore *int
-		if g.cardPlayed == stronger {
-			if g.isOpponentMove {
-				winnerScore = &g.score
-			} else {
-				winnerScore = &g.opponentScore
-			}
+	if cardPlayed != nil {
+		return move
+	}
 
-			g.isOpponentMove = !g.isOpponentMove
-		} else {
-			if g.isOpponentMove {
-				winnerScore = &g.opponentScore
-			} else {
-				winnerScore = &g.score
-			}
+	if move.Card.Rank == santase.Queen || move.Card.Rank == santase.King {
+		announced := move
+		announced.IsAnnouncement = true
+		if g.Validate(announced) == nil {
+			move = announced
 		}
+	}
 
-		*winnerScore += santase.Points(g.cardPlayed) + santase.Points(&a.card)
-		g.cardPlayed = nil
-		hand.RemoveCard(a.card)
-
-		if !g.isClosed {
-			if len(g.stack) > 1 {
-				if g.isOpponentMove {
-					g.opponentHand.AddCard(g.stack[len(g.stack)-1])
-					g.hand.AddCard(g.stack[len(g.stack)-2])
-				} else {
-					g.hand.AddCard(g.stack[len(g.stack)-1])
-					g.opponentHand.AddCard(g.stack[len(g.stack)-2])
-				}
-				g.stack = g.stack[:len(g.stack)-2]
-			} else if len(g.stack) == 1 {
-				if g.isOpponentMove {
-					g.opponentHand.AddCard(g.stack[0])
-					g.hand.AddCard(*g.trumpCard)
-				} else {
-					g.hand.AddCard(g.stack[0])
-					g.opponentHand.AddCard(*g.trumpCard)
-				}
-				g.stack = nil
-				g.trumpCard = nil
-			}
+	if g.considersClosing() && g.rng.Intn(7) == 0 {
+		closed := move
+		closed.CloseGame = true
+		if g.Validate(closed) == nil {
+			move = closed
 		}
 	}
+
+	return move
 }
 
 func (g *game) runSimulation() int {
-	var hand santase.Hand
-	var a action
-	for g.score < 66 && g.opponentScore < 66 && (len(g.hand) > 0 || len(g.opponentHand) > 0) {
-		hand = g.getHand()
-
-		if g.cardPlayed == nil {
-			card := hand.GetRandomCard()
-			// check if switching is possible
-			if card == santase.NewCard(santase.Nine, g.trump) && !g.isClosed && len(g.stack) > 1 && len(g.stack) < 11 {
-				// TODO: this way playing without switching is not simulated
-				card = *g.trumpCard
-			}
-
-			a = action{card: card}
-			// with probability = 1/7 decide wether to close the game at this turn
-			if g.canClose() && rand.Intn(7) == 0 {
-				a.closeGame = true
-			}
-		} else {
-			if g.trumpCard != nil && !g.isClosed {
-				a = action{card: hand.GetRandomCard()}
-			} else {
-				possibleResponses := hand.GetValidResponses(*g.cardPlayed, g.trump)
-				a = action{card: possibleResponses.GetRandomCard()}
-			}
-		}
-		g.simulate(a)
-	}
-
-	// TODO: 3 points here could potentially be only 2 if a player has a hand with 2 nines
-	if g.score >= 66 && g.opponentScore >= 66 {
-		if g.score > g.opponentScore {
-			return 1
-		} else if g.score < g.opponentScore {
-			return -1
-		} else {
-			if g.isOpponentMove {
-				return -1
-			}
-			return 1
-		}
-	} else if g.score >= 66 {
-		if g.opponentScore == 0 {
-			return 3
-		} else if g.opponentScore < 33 {
-			return 2
-		} else {
-			return 1
+	for !g.IsOver() {
+		if err := g.Apply(g.randomMove()); err != nil {
+			panic(err.Error())
 		}
-	} else if g.opponentScore >= 66 {
-		if g.score == 0 {
-			return -3
-		} else if g.score < 33 {
-			return -2
-		} else {
-			return -1
-		}
-	} else {
-		if g.isOpponentMove {
-			return -1
-		}
-		return 1
 	}
-}
 
-func min(a, b int) int {
-	if a < b {
-		return a
+	winner, points := g.GetTally().Result()
+	if winner == santase.Opponent {
+		return -points
 	}
-	return b
+	return points
 }
 
-func sample(g *santase.Game) game {
+// sample returns a determinization of game, in which the unseen
+// cards are dealt at random.
+func sample(g *santase.Game, rng *rand.Rand) game {
 	unseenCards := g.GetUnseenCards()
-	hiddenCards := unseenCards.ToSlice()
-	rand.Shuffle(len(hiddenCards), func(i, j int) {
-		hiddenCards[i], hiddenCards[j] = hiddenCards[j], hiddenCards[i]
+	unseen := unseenCards.ToSlice()
+	rng.Shuffle(len(unseen), func(i, j int) {
+		unseen[i], unseen[j] = unseen[j], unseen[i]
 	})
 
-	hand := g.GetHand()
-	knownOpponentCards := g.GetKnownOpponentCards()
-
-	splitAt := len(hand) - len(knownOpponentCards)
-	if !g.IsOpponentMove() && g.GetCardPlayed() != nil {
-		splitAt--
-	}
-
-	opponentHand := knownOpponentCards
-	for _, card := range hiddenCards[:min(len(hiddenCards), splitAt)] {
-		opponentHand.AddCard(card)
-	}
-
-	trumpCard := g.GetTrumpCard()
-	var stack []santase.Card
-	if trumpCard != nil {
-		stack = hiddenCards[min(len(hiddenCards), splitAt):]
-	}
-
-	return game{
-		score:          g.GetScore(),
-		opponentScore:  g.GetOpponentScore(),
-		hand:           hand,
-		opponentHand:   opponentHand,
-		trump:          g.GetTrump(),
-		stack:          stack,
-		trumpCard:      trumpCard,
-		cardPlayed:     g.GetCardPlayed(),
-		isOpponentMove: g.IsOpponentMove(),
-		isClosed:       g.IsClosed(),
+	state, err := g.State(unseen)
+	if err != nil {
+		panic(err.Error())
 	}
+	return game{State: state, rng: rng}
 }
 
-func selectNode(root *node, game *game, c float64) *node {
+// selectNode descends from root down the tree, playing the actions
+// of the nodes it passes in game, until it reaches a node that is not
+// fully expanded or the end of the game. It returns the node and the
+// legal actions at it.
+func selectNode(root *node, game *game, c float64) (*node, []action) {
 	v := root
 
-	for v.isExpanded(game) && !v.isTerminal() {
+	for !game.IsOver() {
+		actions := game.legalActions()
+		if !v.isExpanded(actions) || v.isTerminal() {
+			return v, actions
+		}
+
 		// descend down the tree using modified UCB1
 		bestScore := math.Inf(-1)
 		var bestChild *node
 		var bestAction action
-		canCloseGame := game.canClose()
-		for card := range game.getHand() {
-			if !game.isCardLegal(card) {
-				continue
-			}
-			a := action{card: card}
+		for _, a := range actions {
 			u := v.children[a]
 
 			f := float64(u.score) / float64(u.visits)
-			if game.isOpponentMove {
+			if game.IsOpponentMove() {
 				f *= -1
 			}
 			g := c * math.Sqrt(2*math.Log(float64(u.availability))/float64(u.visits))
@@ -450,18 +331,6 @@ func selectNode(root *node, game *game, c float64) *node {
 				bestAction = a
 			}
 			u.availability++
-
-			if canCloseGame {
-				a := action{card: card, closeGame: true}
-				u = v.children[a]
-				score = float64(u.score)/float64(u.visits) + c*math.Sqrt(2*math.Log(float64(u.availability))/float64(u.visits))
-				if score > bestScore {
-					bestScore = score
-					bestChild = u
-					bestAction = a
-				}
-				u.availability++
-			}
 		}
 
 		v = bestChild
@@ -469,176 +338,326 @@ func selectNode(root *node, game *game, c float64) *node {
 		game.simulate(bestAction)
 	}
 
-	return v
+	return v, nil
 }
 
-func toMove(game *santase.Game, bestAction action) santase.Move {
-	hand := game.GetHand()
-	seenCards := game.GetSeenCards()
-	cardPlayed := game.GetCardPlayed()
+// soismcts follows the pseudo code described in the paper
+// "Information Set Monte Carlo Tree Search". It continues the search
+// of the tree at root (or starts a new tree if root is nil) and returns
+// the root of the tree. It runs until the given number of iterations is
+// done (there is no limit if it is negative) or until ctx is done. Unless
+// iterations is 0, at least one iteration is run, so the root has a
+// child to choose.
+func (a *Agent) soismcts(ctx context.Context, game *santase.Game, root *node, rng *rand.Rand, iterations int) *node {
+	if root == nil {
+		root = &node{children: make(map[action]*node)}
+	}
 
-	// check if switching is possible
-	switchTrumpCard := false
-	if cardPlayed == nil && len(seenCards) > 0 && len(seenCards) < 10 {
-		nineTrump := santase.NewCard(santase.Nine, game.GetTrump())
-		if nineTrump != bestAction.card && hand.HasCard(nineTrump) {
-			switchTrumpCard = true
+	for i := 0; iterations < 0 || i < iterations; i++ {
+		if i > 0 && ctx.Err() != nil {
+			break
 		}
-	}
 
-	// check if announcing is possible
-	isAnnouncement := false
-	if cardPlayed == nil && len(seenCards) != 0 &&
-		(bestAction.card.Rank == santase.Queen || bestAction.card.Rank == santase.King) {
-		var other santase.Card
-		if bestAction.card.Rank == santase.Queen {
-			other = santase.NewCard(santase.King, bestAction.card.Suit)
-		} else {
-			other = santase.NewCard(santase.Queen, bestAction.card.Suit)
+		// choose a determinization at random compatible with the game
+		// this iteration will use only actions compatible with the
+		// selected determinization
+		g := sample(game, rng)
+
+		// select which node to expand
+		v, actions := selectNode(root, &g, a.c)
+
+		// expand the tree if the selected node is not fully expanded
+		if !g.IsOver() && !v.isExpanded(actions) {
+			v = v.expandRandomChild(&g, actions)
 		}
 
-		if hand.HasCard(other) || (switchTrumpCard && *game.GetTrumpCard() == other) {
-			isAnnouncement = true
+		// simulate the game till the end using random moves
+		points := g.runSimulation()
+
+		// backpropagation
+		for v.parent != nil {
+			v.score += points
+			if points > 0 {
+				v.wins++
+				v.gamePoints += points
+			}
+			v = v.parent
 		}
 	}
 
-	return santase.Move{
-		Card:            bestAction.card,
-		SwitchTrumpCard: switchTrumpCard,
-		IsAnnouncement:  isAnnouncement,
-		CloseGame:       bestAction.closeGame,
-	}
-}
-
-// SOISMCTS follows the pseudo code described in the paper
-// "Information Set Monte Carlo Tree Search"
-func (a *agent) SOISMCTS(game *santase.Game, results chan *node, quit chan struct{}) {
-	root := node{children: make(map[action]*node)}
-
-loop:
-	for {
-		select {
-		case <-quit:
-			break loop
-		default:
-			// choose a determinization at random compatible with the game
-			// this iteration will use only actions compatible with the
-			// selected determinization
-			g := sample(game)
-
-			// select which node to expand
-			v := selectNode(&root, &g, a.c)
-
-			// expand the tree if the selected node is not fully expanded
-			if !v.isExpanded(&g) {
-				v = v.expandRandomChild(&g)
-			}
+	return root
+}
 
-			// simulate the game till the end using random moves
-			points := g.runSimulation()
+// withTimeLimit returns a context that is done when the time per move
+// runs out (or when ctx is done). There is no time limit if timePerMove
+// is 0.
+func (a *Agent) withTimeLimit(ctx context.Context) (context.Context, context.CancelFunc) {
+	if a.timePerMove == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, a.timePerMove)
+}
 
-			// backpropagation
-			for v.parent != nil {
-				v.score += points
-				v = v.parent
-			}
-		}
+// workerIterations returns how many of the given iterations the i-th
+// worker out of n should run (see soismcts). There is no limit if
+// iterations is 0, and a worker gets no iterations if there are fewer
+// iterations than workers.
+func workerIterations(iterations, i, n int) int {
+	if iterations == 0 {
+		return -1
+	}
+
+	result := iterations / n
+	if i < iterations%n {
+		result++
+	}
+	return result
+}
+
+// search runs one worker for each of the roots (see soismcts) and
+// returns the trees they built, in the same order. The iterations are
+// split between the workers (see workerIterations).
+// Each worker uses its own random source (see newRands).
+func (a *Agent) search(ctx context.Context, game *santase.Game, roots []*node, rngs []*rand.Rand, iterations int) []*node {
+	var wg sync.WaitGroup
+	for i := range roots {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			roots[i] = a.soismcts(ctx, game, roots[i], rngs[i], workerIterations(iterations, i, len(roots)))
+		}(i)
 	}
+	wg.Wait()
+
+	return roots
+}
 
-	results <- &root
+// newRands returns a random source for each of n workers, seeded from
+// the random source of the agent. They are created before any of the
+// workers starts, so the same seed always gives each worker the same
+// source.
+func (a *Agent) newRands(n int) []*rand.Rand {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	rngs := make([]*rand.Rand, n)
+	for i := range rngs {
+		rngs[i] = rand.New(rand.NewSource(a.rng.Int63()))
+	}
+	return rngs
 }
 
 // singleObserverInformationSetMCTS is a single threaded ISMCTS
 // implementation. It is equivalent with
 // singleObserverInformationSetMCTSRootParallelization if ran
-// on a machine with one cpu.
+// with one worker.
 // This version can be easier to debug.
-func (a *agent) singleObserverInformationSetMCTS(game *santase.Game) santase.Move {
-	results := make(chan *node)
-	quit := make(chan struct{})
-
-	go func() {
-		<-time.After(a.timePerMove)
-		close(quit)
-	}()
+func (a *Agent) singleObserverInformationSetMCTS(ctx context.Context, game *santase.Game) santase.Move {
+	ctx, cancel := a.withTimeLimit(ctx)
+	defer cancel()
 
-	go a.SOISMCTS(game, results, quit)
-	root := <-results
+	root := a.soismcts(ctx, game, nil, a.newRands(1)[0], workerIterations(a.iterations, 0, 1))
 
 	// return best move
-	var bestAction action
-	var maxVisits = 0
-	for a, v := range root.children {
-		if v.visits > maxVisits {
-			maxVisits = v.visits
-			bestAction = a
-		}
-	}
-
-	return toMove(game, bestAction)
+	return candidates(game, []*node{root})[0].Move
 }
 
 // singleObserverInformationSetMCTSRootParallelization implements
 // ISMCTS with root parallelization as defined in the paper
 // "Parallelization of Information Set Monte Carlo Tree Search"
-// with as many workers as there are cores on the machine
-func (a *agent) singleObserverInformationSetMCTSRootParallelization(game *santase.Game) santase.Move {
-	results := make(chan *node)
-	quit := make(chan struct{})
+// with as many workers as the agent is configured to use. The search
+// continues the given trees (new trees are started for nil roots) and
+// returns the trees it built together with the statistics of the
+// candidate moves aggregated over all of them.
+func (a *Agent) singleObserverInformationSetMCTSRootParallelization(ctx context.Context, game *santase.Game, roots []*node) ([]*node, []Candidate) {
+	ctx, cancel := a.withTimeLimit(ctx)
+	defer cancel()
+
+	roots = a.search(ctx, game, roots, a.newRands(len(roots)), a.iterations)
+	return roots, candidates(game, roots)
+}
 
-	go func() {
-		<-time.After(a.timePerMove)
-		close(quit)
-	}()
+func actionLess(a, b action) bool {
+	if a.card != b.card {
+		if a.card.Suit != b.card.Suit {
+			return a.card.Suit < b.card.Suit
+		}
+		return a.card.Rank < b.card.Rank
+	}
 
-	numCpus := runtime.NumCPU()
+	flags := func(a action) [4]bool {
+		return [4]bool{a.switchTrumpCard, a.announce, a.closeGame, a.declare}
+	}
+	x, y := flags(a), flags(b)
+	for i := range x {
+		if x[i] != y[i] {
+			return !x[i]
+		}
+	}
+	return false
+}
 
-	for i := 0; i < numCpus; i++ {
-		go a.SOISMCTS(game, results, quit)
+// Agent is an agent that searches for moves with ISMCTS. It is
+// created with New or NewAgent.
+//
+// The agent keeps the trees it builds between moves. When it is asked
+// for its next move in the same game, it continues the search from the
+// part of the trees reached by the moves played in the meantime, so it
+// searches deeper in the same time. Besides searching when asked for a
+// move, the agent can search while it is the opponent's turn (see
+// StartPondering).
+//
+// An agent is safe for concurrent use and can play in several games,
+// but it keeps only the trees of the game it searched last.
+type Agent struct {
+	c                float64
+	timePerMove      time.Duration
+	iterations       int
+	ponderIterations int
+	workers          int
+	rng              *rand.Rand
+
+	mu        sync.Mutex
+	pondering *ponder
+	trees     forest
+}
+
+// GetMove searches for a move until the time per move or the
+// iterations run out.
+func (a *Agent) GetMove(game *santase.Game) santase.Move {
+	return a.GetMoveContext(context.Background(), game)
+}
+
+// GetMoveContext searches for a move until the time per move or the
+// iterations run out, or until ctx is done. In any case the best move
+// found so far is returned. The search continues the trees of the
+// previous move (or built while pondering) and they are kept for the
+// next move.
+//
+// Like santase.Game.GetMove, it panics if the game is over, it is not
+// the AI's turn or a drawn card is pending (see Analyze).
+func (a *Agent) GetMoveContext(ctx context.Context, game *santase.Game) santase.Move {
+	if err := checkAITurn(game); err != nil {
+		panic(err.Error())
 	}
 
-	stats := make(map[action]int)
-	for i := 0; i < numCpus; i++ {
-		root := <-results
-		for a, v := range root.children {
-			stats[a] += v.visits
-		}
+	roots, candidates := a.singleObserverInformationSetMCTSRootParallelization(ctx, game, a.takeTrees(game))
+	a.keepTrees(game, roots)
+	return candidates[0].Move
+}
+
+// Option configures an agent created with New.
+type Option func(*Agent)
+
+// WithExploration sets the constant c used in the algorithm that
+// balances exploitation and exploration
+// (https://en.wikipedia.org/wiki/Monte_Carlo_tree_search#Exploration_and_exploitation).
+// The choice of this parameter can affect playing strength.
+// The default is 5.4, which works good.
+func WithExploration(c float64) Option {
+	return func(a *Agent) {
+		a.c = c
 	}
+}
 
-	var bestAction action
-	var maxVisits = 0
-	for a, visits := range stats {
-		if visits > maxVisits {
-			maxVisits = visits
-			bestAction = a
-		}
+// WithTimePerMove sets the maximum time per move the agent is allowed.
+func WithTimePerMove(timePerMove time.Duration) Option {
+	return func(a *Agent) {
+		a.timePerMove = timePerMove
 	}
+}
 
-	return toMove(game, bestAction)
+// WithIterations sets the number of iterations of the search per move,
+// split between the workers. Unless a time per move is also given, the
+// search runs until all iterations are done regardless of how long it
+// takes.
+func WithIterations(iterations int) Option {
+	return func(a *Agent) {
+		a.iterations = iterations
+	}
 }
 
-type agent struct {
-	c           float64
-	timePerMove time.Duration
+// WithPonderIterations sets the number of iterations of a search
+// started with StartPondering, split between the workers. The trees
+// grow with every iteration, so pondering stops by itself once the
+// iterations are done (or runs until it is stopped if iterations is 0).
+// The default is 500000, which takes a few hundred megabytes.
+func WithPonderIterations(iterations int) Option {
+	return func(a *Agent) {
+		a.ponderIterations = iterations
+	}
 }
 
-func (a *agent) GetMove(game *santase.Game) santase.Move {
-	return a.singleObserverInformationSetMCTSRootParallelization(game)
+// WithWorkers sets the number of goroutines that search in parallel.
+// The default is the number of cores on the machine (runtime.NumCPU).
+func WithWorkers(workers int) Option {
+	return func(a *Agent) {
+		a.workers = workers
+	}
+}
+
+// WithSeed seeds the random choices of the agent. An agent with a seed
+// and a number of iterations (see WithIterations) always chooses the
+// same moves in the same games, as long as the searches are not cut
+// short by a time per move.
+func WithSeed(seed int64) Option {
+	return WithRand(rand.New(rand.NewSource(seed)))
+}
+
+// WithRand sets the source of the random choices of the agent. Every
+// worker gets its own source seeded from rng when the agent is asked
+// for a move, so rng is never used concurrently. It should not be
+// shared with other agents.
+func WithRand(rng *rand.Rand) Option {
+	return func(a *Agent) {
+		a.rng = rng
+	}
+}
+
+// New creates a new ISMCTS agent configured with the given options.
+// The search of the agent can be cancelled with a context (see
+// santase.Game.GetMoveContext) and it can ponder (see StartPondering).
+//
+// If neither a time per move nor a number of iterations is given,
+// the agent searches for 2 seconds per move.
+//
+// Panics if the number of workers or iterations is negative.
+func New(options ...Option) *Agent {
+	a := &Agent{
+		c:                5.4,
+		ponderIterations: 500000,
+		workers:          runtime.NumCPU(),
+	}
+	for _, option := range options {
+		option(a)
+	}
+
+	if a.workers < 1 {
+		panic("at least one worker is needed")
+	}
+	if a.iterations < 0 || a.ponderIterations < 0 {
+		panic("negative number of iterations")
+	}
+	if a.timePerMove == 0 && a.iterations == 0 {
+		a.timePerMove = 2 * time.Second
+	}
+	if a.rng == nil {
+		a.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return a
 }
 
 // NewAgent creates a new ISMCTS agent.
 //
 // The first parameter c is a constant used in the algorithm
-// that balances exploitation and exploration
-// (https://en.wikipedia.org/wiki/Monte_Carlo_tree_search#Exploration_and_exploitation).
-// The choice of this parameter can affect playing strength.
+// that balances exploitation and exploration (see WithExploration).
 // A value around 5.4 works good.
 //
 // The second parameter timePerMove chooses the maximum time
 // per move the agent is allowed.
+//
+// NewAgent(c, timePerMove) is the same as
+// New(WithExploration(c), WithTimePerMove(timePerMove)).
 func NewAgent(c float64, timePerMove time.Duration) santase.Agent {
-	return &agent{
-		c:           5.4,
-		timePerMove: 2 * time.Second,
-	}
+	return New(WithExploration(c), WithTimePerMove(timePerMove))
 }
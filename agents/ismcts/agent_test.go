@@ -0,0 +1,133 @@
+package ismcts
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	santase "github.com/nvlbg/santase-ai"
+	"github.com/stretchr/testify/assert"
+)
+
+func createSampleGame() santase.Game {
+	hand := santase.NewHand(
+		santase.NewCard(santase.Nine, santase.Diamonds),
+		santase.NewCard(santase.King, santase.Spades),
+		santase.NewCard(santase.Queen, santase.Diamonds),
+		santase.NewCard(santase.Nine, santase.Spades),
+		santase.NewCard(santase.Ace, santase.Spades),
+		santase.NewCard(santase.Ten, santase.Hearts),
+	)
+	return santase.CreateGame(hand, santase.NewCard(santase.Ten, santase.Clubs), false)
+}
+
+// finishes fails the test if f does not return in time.
+func finishes(t *testing.T, f func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("did not finish in time")
+	}
+}
+
+func TestWorkerIterations(t *testing.T) {
+	assert.Equal(t, []int{3, 3, 2}, []int{
+		workerIterations(8, 0, 3),
+		workerIterations(8, 1, 3),
+		workerIterations(8, 2, 3),
+	})
+	assert.Equal(t, []int{1, 1, 0, 0}, []int{
+		workerIterations(2, 0, 4),
+		workerIterations(2, 1, 4),
+		workerIterations(2, 2, 4),
+		workerIterations(2, 3, 4),
+	})
+	assert.Equal(t, -1, workerIterations(0, 2, 4))
+}
+
+func TestFewerIterationsThanWorkers(t *testing.T) {
+	game := createSampleGame()
+	agent := New(WithIterations(2), WithWorkers(4), WithSeed(1))
+
+	finishes(t, func() {
+		move := agent.GetMove(&game)
+		assert.Nil(t, game.ValidateMove(move))
+	})
+
+	finishes(t, func() {
+		candidates, err := Analyze(&game, Budget{Iterations: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, candidates[0].Visits)
+	})
+}
+
+func TestConcurrentMoves(t *testing.T) {
+	agent := New(WithIterations(200), WithWorkers(2), WithSeed(1))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			game := createSampleGame()
+			move := agent.GetMove(&game)
+			assert.Nil(t, game.ValidateMove(move))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSeededAgentsAgree(t *testing.T) {
+	analyze := func(seed int64) []Candidate {
+		game := createSampleGame()
+		candidates, err := New(WithIterations(2000), WithWorkers(3), WithSeed(seed)).Analyze(context.Background(), &game)
+		assert.Nil(t, err)
+		return candidates
+	}
+
+	assert.Equal(t, analyze(1), analyze(1))
+	assert.NotEqual(t, analyze(1), analyze(2))
+
+	play := func() santase.DealResult {
+		table := santase.NewTable(
+			New(WithIterations(300), WithWorkers(2), WithSeed(1)),
+			New(WithIterations(300), WithWorkers(2), WithSeed(2)),
+		)
+		table.SetRand(rand.New(rand.NewSource(1)))
+		result, err := table.Play()
+		assert.Nil(t, err)
+		return result
+	}
+
+	assert.Equal(t, play(), play())
+}
+
+func TestCancelledSearch(t *testing.T) {
+	agent := New(WithTimePerMove(time.Hour), WithSeed(1))
+
+	// the move is chosen after one iteration if ctx is done already
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	finishes(t, func() {
+		game := createSampleGame()
+		move := agent.GetMoveContext(ctx, &game)
+		assert.Nil(t, game.ValidateMove(move))
+	})
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	finishes(t, func() {
+		game := createSampleGame()
+		game.SetAgent(agent)
+		_, err := game.TryGetMoveContext(ctx)
+		assert.Nil(t, err)
+	})
+}
@@ -0,0 +1,131 @@
+package ismcts
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	santase "github.com/nvlbg/santase-ai"
+)
+
+// Candidate is a move the AI can play together with
+// the statistics the search collected for it.
+//
+// The statistics are from the point of view of the AI and are
+// aggregated over the trees of all workers. MeanReward is the average
+// outcome of the simulations after the move in game points (positive
+// when the AI wins and negative when it loses), WinProbability is the
+// part of the simulations the AI won and ExpectedGamePoints is the
+// average number of game points the AI won. All of them are 0 if the
+// move was never visited.
+type Candidate struct {
+	Move               santase.Move
+	Visits             int
+	MeanReward         float64
+	WinProbability     float64
+	ExpectedGamePoints float64
+}
+
+// Budget limits how long Analyze searches. The search stops when
+// either the time or the iterations run out. A zero value of either
+// field means that it does not limit the search. If neither is set
+// the search takes 2 seconds (like the default of New).
+type Budget struct {
+	Time       time.Duration
+	Iterations int
+}
+
+// Analyze searches for the move of the AI in game with the default
+// options of New and returns all candidate moves with their statistics
+// (see Agent.Analyze).
+func Analyze(game *santase.Game, budget Budget) ([]Candidate, error) {
+	agent := New(WithTimePerMove(budget.Time), WithIterations(budget.Iterations))
+	return agent.Analyze(context.Background(), game)
+}
+
+// Analyze searches for the move of the AI in game like GetMoveContext
+// and returns all of its legal moves (see santase.Game.LegalMoves) with
+// their statistics, sorted from the strongest to the weakest. The first
+// candidate is the move the agent would play (the one visited the most).
+// Moves the search never explored, like declarations that do not win,
+// have no visits.
+//
+// Unlike GetMoveContext, the search starts new trees and they are not
+// kept, so analyzing a position does not change the trees the agent
+// continues on its next move.
+//
+// An error is returned if the game is over, it is not the AI's turn
+// or a drawn card is pending.
+func (a *Agent) Analyze(ctx context.Context, game *santase.Game) ([]Candidate, error) {
+	if err := checkAITurn(game); err != nil {
+		return nil, err
+	}
+
+	_, candidates := a.singleObserverInformationSetMCTSRootParallelization(ctx, game, make([]*node, a.workers))
+	return candidates, nil
+}
+
+// checkAITurn returns an error if the AI cannot move in game.
+func checkAITurn(game *santase.Game) error {
+	if game.IsOver() {
+		return santase.ErrGameOver
+	}
+	if game.IsOpponentMove() {
+		return santase.ErrNotAITurn
+	}
+	if game.IsDrawPending() {
+		return santase.ErrDrawPending
+	}
+	return nil
+}
+
+// candidates aggregates the statistics of the actions at the roots
+// of the trees for each legal move of the AI and returns them sorted
+// by visits.
+func candidates(game *santase.Game, roots []*node) []Candidate {
+	stats := make(map[action]*node)
+	for _, move := range game.LegalMoves() {
+		stats[toAction(move)] = &node{}
+	}
+
+	for _, root := range roots {
+		for a, v := range root.children {
+			// a tree continued from a previous move can have actions
+			// with cards the AI held only in some determinizations
+			total, ok := stats[a]
+			if !ok {
+				continue
+			}
+			total.visits += v.visits
+			total.score += v.score
+			total.wins += v.wins
+			total.gamePoints += v.gamePoints
+		}
+	}
+
+	actions := make([]action, 0, len(stats))
+	for a := range stats {
+		actions = append(actions, a)
+	}
+	// ties are broken in a fixed order of the actions, so that the
+	// result does not depend on the order of iteration over the map
+	sort.Slice(actions, func(i, j int) bool {
+		if stats[actions[i]].visits != stats[actions[j]].visits {
+			return stats[actions[i]].visits > stats[actions[j]].visits
+		}
+		return actionLess(actions[i], actions[j])
+	})
+
+	result := make([]Candidate, 0, len(actions))
+	for _, a := range actions {
+		v := stats[a]
+		candidate := Candidate{Move: a.toMove(), Visits: v.visits}
+		if v.visits > 0 {
+			candidate.MeanReward = float64(v.score) / float64(v.visits)
+			candidate.WinProbability = float64(v.wins) / float64(v.visits)
+			candidate.ExpectedGamePoints = float64(v.gamePoints) / float64(v.visits)
+		}
+		result = append(result, candidate)
+	}
+	return result
+}
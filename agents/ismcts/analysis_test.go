@@ -0,0 +1,125 @@
+package ismcts
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	santase "github.com/nvlbg/santase-ai"
+	"github.com/stretchr/testify/assert"
+)
+
+// createGameAfterFirstTrick returns a game in which the AI
+// won the first trick with 13 points and leads the second.
+func createGameAfterFirstTrick() santase.Game {
+	game := createSampleGame()
+	game.SetAgent(fixedAgent{santase.Move{Card: santase.NewCard(santase.Ace, santase.Spades)}})
+	game.GetMove()
+	game.UpdateOpponentMove(santase.Move{Card: santase.NewCard(santase.Jack, santase.Spades)})
+	game.UpdateDrawnCard(santase.NewCard(santase.King, santase.Diamonds))
+	return game
+}
+
+func TestAnalyze(t *testing.T) {
+	game := createGameAfterFirstTrick()
+	agent := New(WithIterations(3000), WithWorkers(2), WithSeed(1))
+
+	candidates, err := agent.Analyze(context.Background(), &game)
+	assert.Nil(t, err)
+
+	// every legal move is a candidate, including closing the game
+	// and declaring without enough points
+	var moves []santase.Move
+	visits := 0
+	for _, candidate := range candidates {
+		moves = append(moves, candidate.Move)
+		visits += candidate.Visits
+	}
+	assert.ElementsMatch(t, game.LegalMoves(), moves)
+	assert.Contains(t, moves, santase.Move{Card: santase.NewCard(santase.Nine, santase.Spades), CloseGame: true})
+	assert.Contains(t, moves, santase.Move{Declare: true})
+	assert.Equal(t, 3000, visits)
+
+	assert.True(t, sort.SliceIsSorted(candidates, func(i, j int) bool {
+		return candidates[i].Visits > candidates[j].Visits
+	}))
+	for _, candidate := range candidates {
+		if candidate.Move.Declare {
+			assert.Equal(t, Candidate{Move: candidate.Move}, candidate)
+		}
+		assert.True(t, candidate.WinProbability >= 0 && candidate.WinProbability <= 1)
+	}
+}
+
+func TestAnalyzeDoesNotKeepTrees(t *testing.T) {
+	game := createGameAfterFirstTrick()
+	agent := New(WithIterations(300), WithWorkers(2), WithSeed(1))
+
+	_, err := agent.Analyze(context.Background(), &game)
+	assert.Nil(t, err)
+	assert.Nil(t, agent.trees.roots)
+
+	// the trees kept from a move are neither continued nor replaced
+	played := createGameAfterFirstTrick()
+	played.SetAgent(agent)
+	played.GetMove()
+	trees := agent.trees
+	kept := visits(trees.roots)
+
+	_, err = agent.Analyze(context.Background(), &game)
+	assert.Nil(t, err)
+	assert.Equal(t, trees, agent.trees)
+	assert.Equal(t, kept, visits(agent.trees.roots))
+}
+
+func TestAnalyzeErrors(t *testing.T) {
+	agent := New(WithIterations(100), WithSeed(1))
+
+	game := createSampleGameOpponentFirst()
+	_, err := agent.Analyze(context.Background(), &game)
+	assert.Equal(t, santase.ErrNotAITurn, err)
+	assert.PanicsWithValue(t, santase.ErrNotAITurn.Error(), func() {
+		agent.GetMove(&game)
+	})
+
+	game.UpdateOpponentMove(santase.Move{Declare: true})
+	_, err = agent.Analyze(context.Background(), &game)
+	assert.Equal(t, santase.ErrGameOver, err)
+	assert.PanicsWithValue(t, santase.ErrGameOver.Error(), func() {
+		agent.GetMove(&game)
+	})
+}
+
+func TestAnalyzeExploresSwitchAndAnnouncements(t *testing.T) {
+	hand := santase.NewHand(
+		santase.NewCard(santase.Nine, santase.Clubs),
+		santase.NewCard(santase.Queen, santase.Diamonds),
+		santase.NewCard(santase.King, santase.Diamonds),
+		santase.NewCard(santase.Ace, santase.Spades),
+		santase.NewCard(santase.Ten, santase.Hearts),
+		santase.NewCard(santase.Nine, santase.Spades),
+	)
+	game := santase.CreateGame(hand, santase.NewCard(santase.Ten, santase.Clubs), false)
+	game.SetAgent(fixedAgent{santase.Move{Card: santase.NewCard(santase.Ace, santase.Spades)}})
+	game.GetMove()
+	game.UpdateOpponentMove(santase.Move{Card: santase.NewCard(santase.Jack, santase.Spades)})
+	game.UpdateDrawnCard(santase.NewCard(santase.Jack, santase.Hearts))
+
+	candidates, err := New(WithIterations(5000), WithWorkers(2), WithSeed(1)).Analyze(context.Background(), &game)
+	assert.Nil(t, err)
+
+	visited := make(map[santase.Move]bool)
+	for _, candidate := range candidates {
+		assert.Nil(t, game.ValidateMove(candidate.Move))
+		if candidate.Visits > 0 {
+			visited[candidate.Move] = true
+		}
+	}
+
+	queen := santase.NewCard(santase.Queen, santase.Diamonds)
+	assert.True(t, visited[santase.Move{Card: queen}])
+	assert.True(t, visited[santase.Move{Card: queen, IsAnnouncement: true}])
+	assert.True(t, visited[santase.Move{Card: queen, SwitchTrumpCard: true}])
+	assert.True(t, visited[santase.Move{Card: queen, IsAnnouncement: true, SwitchTrumpCard: true}])
+	assert.True(t, visited[santase.Move{Card: santase.NewCard(santase.Ten, santase.Clubs), SwitchTrumpCard: true}])
+}
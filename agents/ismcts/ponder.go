@@ -0,0 +1,175 @@
+package ismcts
+
+import (
+	"context"
+
+	santase "github.com/nvlbg/santase-ai"
+)
+
+// forest holds the trees built by the workers of a search together
+// with the record of the game they were built for (see santase.Record).
+type forest struct {
+	roots  []*node
+	record santase.Record
+}
+
+// advance returns the trees advanced to the current position of game
+// through the moves played since they were built. The root of a tree
+// is replaced with nil (a new tree) if the moves played were never
+// explored in it, and all of them are if the trees were built for a
+// different deal or for a different number of workers.
+func (f *forest) advance(game *santase.Game, workers int) []*node {
+	roots := make([]*node, workers)
+
+	record := santase.NewRecord(game)
+	if len(f.roots) != workers || !sameDeal(f.record, record) {
+		return roots
+	}
+
+	history := record.Events
+	if len(history) < len(f.record.Events) {
+		return roots
+	}
+	for i, event := range f.record.Events {
+		if history[i] != event {
+			return roots
+		}
+	}
+
+	for i, root := range f.roots {
+		for _, event := range history[len(f.record.Events):] {
+			if root == nil {
+				break
+			}
+			if event.Kind == santase.EventMove {
+				root = root.children[toAction(event.Move)]
+			}
+		}
+
+		if root != nil {
+			root.parent = nil
+		}
+		roots[i] = root
+	}
+	return roots
+}
+
+// sameDeal returns if the records are of the same deal from the point
+// of view of the AI: it was dealt the same cards with the same trump
+// card, the same player led first and the rules are the same.
+func sameDeal(a, b santase.Record) bool {
+	return a.Hand == b.Hand &&
+		a.TrumpCard == b.TrumpCard &&
+		a.IsOpponentFirst == b.IsOpponentFirst &&
+		a.Rules == b.Rules
+}
+
+// toAction returns the action in the search tree of a move.
+func toAction(move santase.Move) action {
+	return action{
+		card:            move.Card,
+		switchTrumpCard: move.SwitchTrumpCard,
+		announce:        move.IsAnnouncement,
+		closeGame:       move.CloseGame,
+		declare:         move.Declare,
+	}
+}
+
+// ponder is a search running in the background (see StartPondering).
+type ponder struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	trees  forest
+}
+
+// StartPondering starts searching in the background from the current
+// position of game, which is usually when the agent waits for the move
+// of the opponent. Like a search for a move it continues the trees of
+// the previous move. The search continues until StopPondering is called,
+// the agent is asked for its next move, which continues the search in
+// the part of the trees reached by the moves played in the meantime, or
+// the iterations of pondering run out (see WithPonderIterations).
+//
+// The game can be updated while the agent ponders, because the search
+// uses its own copy of the game. Pondering again stops the previous
+// search.
+//
+// Pondering keeps all the workers of the agent busy, so servers that
+// host many games may prefer to not use it. An error is returned if the
+// game is over or a drawn card is pending.
+func (a *Agent) StartPondering(game *santase.Game) error {
+	if game.IsOver() {
+		return santase.ErrGameOver
+	}
+	if game.IsDrawPending() {
+		return santase.ErrDrawPending
+	}
+
+	position, err := santase.RestoreGame(game.Snapshot())
+	if err != nil {
+		return err
+	}
+
+	rngs := a.newRands(a.workers)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stopPondering()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &ponder{cancel: cancel, done: make(chan struct{})}
+	roots := a.trees.advance(&position, a.workers)
+	a.trees = forest{}
+	record := santase.NewRecord(&position)
+	go func() {
+		defer close(p.done)
+		p.trees = forest{
+			roots:  a.search(ctx, &position, roots, rngs, a.ponderIterations),
+			record: record,
+		}
+	}()
+
+	a.pondering = p
+	return nil
+}
+
+// StopPondering stops the search started with StartPondering. The
+// trees built so far are kept for the next move of the agent. It is
+// a noop if the agent is not pondering.
+func (a *Agent) StopPondering() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stopPondering()
+}
+
+func (a *Agent) stopPondering() {
+	if a.pondering == nil {
+		return
+	}
+
+	a.pondering.cancel()
+	<-a.pondering.done
+	a.trees = a.pondering.trees
+	a.pondering = nil
+}
+
+// takeTrees stops pondering and returns the trees to continue the
+// search from at the current position of game (see forest.advance).
+// The trees are no longer kept by the agent.
+func (a *Agent) takeTrees(game *santase.Game) []*node {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stopPondering()
+
+	roots := a.trees.advance(game, a.workers)
+	a.trees = forest{}
+	return roots
+}
+
+// keepTrees keeps the trees built for the current
+// position of game to continue them on the next move.
+func (a *Agent) keepTrees(game *santase.Game, roots []*node) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.trees = forest{roots: roots, record: santase.NewRecord(game)}
+}
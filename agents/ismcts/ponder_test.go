@@ -0,0 +1,192 @@
+package ismcts
+
+import (
+	"testing"
+
+	santase "github.com/nvlbg/santase-ai"
+	"github.com/stretchr/testify/assert"
+)
+
+func createSampleGameOpponentFirst() santase.Game {
+	game := createSampleGame()
+	return santase.CreateGame(game.GetHand(), *game.GetTrumpCard(), true)
+}
+
+// visits returns the number of iterations run in the trees.
+func visits(roots []*node) int {
+	result := 0
+	for _, root := range roots {
+		if root == nil {
+			continue
+		}
+		for _, child := range root.children {
+			result += child.visits
+		}
+	}
+	return result
+}
+
+func TestPondering(t *testing.T) {
+	game := createSampleGameOpponentFirst()
+	agent := New(WithIterations(100), WithPonderIterations(2000), WithWorkers(2), WithSeed(1))
+	game.SetAgent(agent)
+
+	assert.Nil(t, agent.StartPondering(&game))
+	finishes(t, func() {
+		<-agent.pondering.done
+	})
+	agent.StopPondering()
+	assert.Nil(t, agent.pondering)
+	pondered := visits(agent.trees.roots)
+	assert.True(t, pondered > 0)
+
+	// the move of the opponent was explored while pondering, so the
+	// search for the move of the AI continues the trees
+	game.UpdateOpponentMove(santase.Move{Card: santase.NewCard(santase.Jack, santase.Spades)})
+	roots := agent.takeTrees(&game)
+	assert.True(t, visits(roots) > 0)
+	assert.True(t, visits(roots) < pondered)
+	for _, root := range roots {
+		assert.Nil(t, root.parent)
+	}
+
+	// stopping again is a noop
+	agent.StopPondering()
+}
+
+func TestPonderingStopsByItself(t *testing.T) {
+	game := createSampleGameOpponentFirst()
+	agent := New(WithIterations(100), WithPonderIterations(300), WithWorkers(2), WithSeed(1))
+
+	assert.Nil(t, agent.StartPondering(&game))
+	finishes(t, func() {
+		<-agent.pondering.done
+	})
+	agent.StopPondering()
+	assert.Equal(t, 300, visits(agent.trees.roots))
+}
+
+func TestPonderingAgain(t *testing.T) {
+	game := createSampleGameOpponentFirst()
+	agent := New(WithIterations(100), WithPonderIterations(300), WithWorkers(2), WithSeed(1))
+
+	assert.Nil(t, agent.StartPondering(&game))
+	assert.Nil(t, agent.StartPondering(&game))
+	finishes(t, func() {
+		<-agent.pondering.done
+	})
+	agent.StopPondering()
+
+	// the second search continued the trees of the first one
+	assert.True(t, visits(agent.trees.roots) > 300)
+}
+
+func TestPonderingErrors(t *testing.T) {
+	agent := New(WithIterations(100), WithSeed(1))
+
+	game := createSampleGameOpponentFirst()
+	game.SetAgent(fixedAgent{santase.Move{Card: santase.NewCard(santase.Ace, santase.Spades)}})
+	game.UpdateOpponentMove(santase.Move{Card: santase.NewCard(santase.Jack, santase.Spades)})
+	game.GetMove()
+	assert.Equal(t, santase.ErrDrawPending, agent.StartPondering(&game))
+
+	game = createSampleGameOpponentFirst()
+	game.UpdateOpponentMove(santase.Move{Declare: true})
+	assert.Equal(t, santase.ErrGameOver, agent.StartPondering(&game))
+	assert.Nil(t, agent.pondering)
+}
+
+// fixedAgent always plays the same move.
+type fixedAgent struct {
+	move santase.Move
+}
+
+func (a fixedAgent) GetMove(game *santase.Game) santase.Move {
+	return a.move
+}
+
+func TestForestAdvance(t *testing.T) {
+	aceSpades := action{card: santase.NewCard(santase.Ace, santase.Spades)}
+	jackSpades := action{card: santase.NewCard(santase.Jack, santase.Spades)}
+
+	game := createSampleGame()
+	response := &node{children: make(map[action]*node)}
+	played := &node{children: map[action]*node{jackSpades: response}}
+	response.parent = played
+	root := &node{children: map[action]*node{aceSpades: played}}
+	played.parent = root
+	other := &node{children: make(map[action]*node)}
+	trees := forest{roots: []*node{root, other}, record: santase.NewRecord(&game)}
+
+	game.SetAgent(fixedAgent{aceSpades.toMove()})
+	game.GetMove()
+	game.UpdateOpponentMove(jackSpades.toMove())
+	game.UpdateDrawnCard(santase.NewCard(santase.Jack, santase.Hearts))
+
+	// the second tree never explored the moves played
+	roots := trees.advance(&game, 2)
+	assert.Equal(t, []*node{response, nil}, roots)
+	assert.Nil(t, response.parent)
+
+	// the trees were built for a different number of workers
+	assert.Equal(t, []*node{nil, nil, nil}, trees.advance(&game, 3))
+}
+
+func TestForestAdvanceOtherGame(t *testing.T) {
+	game := createSampleGame()
+	root := &node{children: make(map[action]*node)}
+	trees := forest{roots: []*node{root}, record: santase.NewRecord(&game)}
+	assert.Equal(t, []*node{root}, trees.advance(&game, 1))
+
+	// the same cards are dealt, but the opponent leads
+	opponentFirst := createSampleGameOpponentFirst()
+	assert.Equal(t, []*node{nil}, trees.advance(&opponentFirst, 1))
+
+	// a different card is dealt
+	hand := game.GetHand()
+	hand.RemoveCard(santase.NewCard(santase.Nine, santase.Spades))
+	hand.AddCard(santase.NewCard(santase.Jack, santase.Spades))
+	otherHand := santase.CreateGame(hand, *game.GetTrumpCard(), false)
+	assert.Equal(t, []*node{nil}, trees.advance(&otherHand, 1))
+
+	// the same deal with other rules
+	rules := santase.StandardRules
+	rules.TargetScore = 33
+	otherRules := santase.CreateGameWithRules(game.GetHand(), *game.GetTrumpCard(), false, rules)
+	assert.Equal(t, []*node{nil}, trees.advance(&otherRules, 1))
+
+	// the moves played differ from the ones the trees were built after
+	game.SetAgent(fixedAgent{santase.Move{Card: santase.NewCard(santase.Ace, santase.Spades)}})
+	game.GetMove()
+	trees = forest{roots: []*node{root}, record: santase.NewRecord(&game)}
+	otherMove := createSampleGame()
+	otherMove.SetAgent(fixedAgent{santase.Move{Card: santase.NewCard(santase.Nine, santase.Spades)}})
+	otherMove.GetMove()
+	assert.Equal(t, []*node{nil}, trees.advance(&otherMove, 1))
+}
+
+func TestAgentPlaysSeveralGames(t *testing.T) {
+	agent := New(WithIterations(300), WithWorkers(2), WithSeed(1))
+
+	game := createSampleGame()
+	game.SetAgent(agent)
+	game.GetMove()
+
+	// a new game with an empty history does not continue the trees
+	// of the first game, in which the AI played a card already
+	hand := santase.NewHand(
+		santase.NewCard(santase.Nine, santase.Hearts),
+		santase.NewCard(santase.Jack, santase.Hearts),
+		santase.NewCard(santase.Queen, santase.Hearts),
+		santase.NewCard(santase.King, santase.Hearts),
+		santase.NewCard(santase.Ten, santase.Hearts),
+		santase.NewCard(santase.Ace, santase.Hearts),
+	)
+	other := santase.CreateGame(hand, santase.NewCard(santase.Ten, santase.Clubs), false)
+	assert.Equal(t, []*node{nil, nil}, agent.takeTrees(&other))
+
+	other.SetAgent(agent)
+	move := other.GetMove()
+	hand = other.GetHand()
+	assert.False(t, hand.HasCard(move.Card))
+}
@@ -1,25 +1,64 @@
 // Package random provides a random agent that always plays random valid move.
+// The agent declares as soon as it has collected enough points to win.
 package random
 
 import (
+	"math/rand"
+
 	santase "github.com/nvlbg/santase-ai"
 )
 
-type agent struct{}
+type agent struct {
+	rng *rand.Rand
+}
 
 func (a *agent) GetMove(game *santase.Game) santase.Move {
-	hand := game.GetHand()
-	cardPlayed := game.GetCardPlayed()
-	if cardPlayed != nil && (game.IsClosed() || game.GetTrumpCard() == nil) {
-		hand = hand.GetValidResponses(*cardPlayed, game.GetTrump())
+	if game.GetCardPlayed() == nil && game.GetScore() >= game.GetRules().TargetScore {
+		return santase.Move{Declare: true}
+	}
+
+	// the cards that can be played without switching,
+	// closing or announcing
+	var cards santase.Hand
+	for _, move := range game.LegalMoves() {
+		if move == (santase.Move{Card: move.Card}) {
+			cards.AddCard(move.Card)
+		}
 	}
 
 	return santase.Move{
-		Card: hand.GetRandomCard(),
+		Card: cards.GetRandomCardFrom(a.rng),
+	}
+}
+
+// Option configures an agent created with New.
+type Option func(*agent)
+
+// WithSeed seeds the random choices of the agent,
+// so that it always plays the same moves in the same games.
+func WithSeed(seed int64) Option {
+	return WithRand(rand.New(rand.NewSource(seed)))
+}
+
+// WithRand sets the source of the random choices of the agent.
+// It should not be shared with other agents.
+func WithRand(rng *rand.Rand) Option {
+	return func(a *agent) {
+		a.rng = rng
+	}
+}
+
+// New creates a new random agent configured with the given options.
+// By default the global source of math/rand is used.
+func New(options ...Option) santase.Agent {
+	a := &agent{}
+	for _, option := range options {
+		option(a)
 	}
+	return a
 }
 
 // NewAgent creates a new random agent.
 func NewAgent() santase.Agent {
-	return &agent{}
+	return New()
 }
@@ -0,0 +1,125 @@
+package santase
+
+import (
+	"math/bits"
+	"math/rand"
+)
+
+// CardSet is a set of cards represented as a bitmask
+// with one bit for each card, in the order of AllCards.
+//
+// Sets are plain values, so they can be copied and compared with ==
+// without allocating. The cards of a set are always listed in the
+// order of AllCards (by suit and then by rank). Hand and Pile are
+// backed by a CardSet and can be converted to and from one, e.g.
+// CardSet(hand).Union(CardSet(pile)).
+type CardSet uint32
+
+// FullDeck is the set of all cards in the game (see AllCards).
+const FullDeck CardSet = 1<<24 - 1
+
+// NewCardSet creates a set containing the passed cards.
+func NewCardSet(cards ...Card) CardSet {
+	var s CardSet
+	for _, card := range cards {
+		s.Add(card)
+	}
+	return s
+}
+
+// SuitSet returns the set of all cards of the given suit.
+func SuitSet(suit Suit) CardSet {
+	return (1<<6 - 1) << (6 * uint(suit))
+}
+
+// bit returns the bit of a card in a CardSet.
+func (c Card) bit() CardSet {
+	return 1 << (6*uint(c.Suit) + uint(c.Rank))
+}
+
+// Add adds the card to the set.
+func (s *CardSet) Add(c Card) {
+	*s |= c.bit()
+}
+
+// Remove removes the card from the set.
+func (s *CardSet) Remove(c Card) {
+	*s &^= c.bit()
+}
+
+// Has checks if the card is in the set.
+func (s CardSet) Has(c Card) bool {
+	return s&c.bit() != 0
+}
+
+// Len returns the number of cards in the set.
+func (s CardSet) Len() int {
+	return bits.OnesCount32(uint32(s))
+}
+
+// IsEmpty checks if there are no cards in the set.
+func (s CardSet) IsEmpty() bool {
+	return s == 0
+}
+
+// Union returns the cards that are in either of the sets.
+func (s CardSet) Union(other CardSet) CardSet {
+	return s | other
+}
+
+// Intersect returns the cards that are in both sets.
+func (s CardSet) Intersect(other CardSet) CardSet {
+	return s & other
+}
+
+// Difference returns the cards of s that are not in other.
+func (s CardSet) Difference(other CardSet) CardSet {
+	return s &^ other
+}
+
+// OfSuit returns the cards of the set that are of the given suit.
+func (s CardSet) OfSuit(suit Suit) CardSet {
+	return s & SuitSet(suit)
+}
+
+// Cards returns the cards in the set in the order of AllCards.
+func (s CardSet) Cards() []Card {
+	result := make([]Card, 0, s.Len())
+	for rest := s; rest != 0; rest &= rest - 1 {
+		result = append(result, AllCards[bits.TrailingZeros32(uint32(rest))])
+	}
+	return result
+}
+
+// Nth returns the n-th card of the set in the order of AllCards.
+//
+// Panics if n is not smaller than the number of cards in the set.
+func (s CardSet) Nth(n int) Card {
+	for rest := s; rest != 0; rest &= rest - 1 {
+		if n == 0 {
+			return AllCards[bits.TrailingZeros32(uint32(rest))]
+		}
+		n--
+	}
+	panic("not enough cards in the set")
+}
+
+// Random returns a card chosen at random from the set using rng,
+// or using the global source of math/rand if rng is nil.
+//
+// Panics if the set is empty.
+func (s CardSet) Random(rng *rand.Rand) Card {
+	if rng == nil {
+		return s.Nth(rand.Intn(s.Len()))
+	}
+	return s.Nth(rng.Intn(s.Len()))
+}
+
+func (s CardSet) String() string {
+	result := "{ "
+	for _, card := range s.Cards() {
+		result += card.String() + " "
+	}
+	result += "}"
+	return result
+}
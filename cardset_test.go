@@ -0,0 +1,77 @@
+package santase
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCardSetOrder(t *testing.T) {
+	for i, card := range AllCards {
+		assert.Equal(t, CardSet(1)<<uint(i), NewCardSet(card))
+	}
+	assert.Equal(t, FullDeck, NewCardSet(AllCards...))
+	assert.Equal(t, AllCards, FullDeck.Cards())
+	assert.Equal(t, 24, FullDeck.Len())
+}
+
+func TestCardSetAlgebra(t *testing.T) {
+	a := NewCardSet(NewCard(Nine, Hearts), NewCard(Ace, Hearts), NewCard(King, Spades))
+	b := NewCardSet(NewCard(Ace, Hearts), NewCard(Ten, Clubs))
+
+	assert.Equal(t, NewCardSet(NewCard(Nine, Hearts), NewCard(Ace, Hearts), NewCard(King, Spades), NewCard(Ten, Clubs)), a.Union(b))
+	assert.Equal(t, NewCardSet(NewCard(Ace, Hearts)), a.Intersect(b))
+	assert.Equal(t, NewCardSet(NewCard(Nine, Hearts), NewCard(King, Spades)), a.Difference(b))
+	assert.Equal(t, NewCardSet(NewCard(Nine, Hearts), NewCard(Ace, Hearts)), a.OfSuit(Hearts))
+	assert.True(t, a.OfSuit(Diamonds).IsEmpty())
+	assert.Equal(t, 6, SuitSet(Spades).Len())
+	assert.Equal(t, FullDeck, SuitSet(Clubs)|SuitSet(Diamonds)|SuitSet(Hearts)|SuitSet(Spades))
+
+	a.Remove(NewCard(Ace, Hearts))
+	a.Add(NewCard(Jack, Diamonds))
+	assert.False(t, a.Has(NewCard(Ace, Hearts)))
+	assert.True(t, a.Has(NewCard(Jack, Diamonds)))
+	assert.Equal(t, 3, a.Len())
+	assert.Equal(t, []Card{NewCard(Jack, Diamonds), NewCard(Nine, Hearts), NewCard(King, Spades)}, a.Cards())
+	assert.Equal(t, NewCard(King, Spades), a.Nth(2))
+	assert.Equal(t, "{ J♦ 9♥ K♠ }", a.String())
+
+	assert.Panics(t, func() { a.Nth(3) })
+}
+
+func TestCardSetRandom(t *testing.T) {
+	set := NewCardSet(NewCard(Nine, Hearts), NewCard(Ace, Spades))
+	seen := NewCardSet()
+	for i := 0; i < 100; i++ {
+		card := set.Random(nil)
+		assert.True(t, set.Has(card))
+		seen.Add(card)
+	}
+	assert.Equal(t, set, seen)
+}
+
+func TestCardSetJSON(t *testing.T) {
+	set := NewCardSet(NewCard(Ten, Hearts), NewCard(Nine, Clubs))
+	data, err := json.Marshal(set)
+	assert.Nil(t, err)
+	assert.Equal(t, `["9C","10H"]`, string(data))
+
+	var decoded CardSet
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, set, decoded)
+
+	assert.NotNil(t, json.Unmarshal([]byte(`["9C","9C"]`), &decoded))
+}
+
+func TestHandIsValue(t *testing.T) {
+	hand := NewHand(NewCard(Nine, Hearts))
+	copied := hand
+	copied.AddCard(NewCard(Ace, Hearts))
+	assert.Equal(t, 1, hand.Len())
+	assert.Equal(t, 2, copied.Len())
+
+	var zero Pile
+	zero.AddCard(NewCard(Ace, Hearts))
+	assert.Equal(t, 1, zero.Len())
+}
@@ -0,0 +1,237 @@
+package santase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidCard is returned when a card, a suit or a rank cannot be
+// decoded from its text representation.
+var ErrInvalidCard = errors.New("invalid card")
+
+// suitLetters are the ASCII letters used to encode suits. The unicode
+// symbols returned by Suit.String are accepted when decoding as well.
+var suitLetters = map[Suit]string{
+	Clubs:    "C",
+	Diamonds: "D",
+	Hearts:   "H",
+	Spades:   "S",
+}
+
+// MarshalText encodes the suit as one of the letters C, D, H or S.
+func (s Suit) MarshalText() ([]byte, error) {
+	letter, ok := suitLetters[s]
+	if !ok {
+		return nil, fmt.Errorf("%w: suit %d", ErrInvalidCard, int(s))
+	}
+	return []byte(letter), nil
+}
+
+// UnmarshalText decodes a suit encoded either as a letter (C, D, H or S
+// in any case) or as a symbol (♣, ♦, ♥ or ♠).
+func (s *Suit) UnmarshalText(text []byte) error {
+	suit, ok := parseSuit(string(text))
+	if !ok {
+		return fmt.Errorf("%w: suit %q", ErrInvalidCard, text)
+	}
+	*s = suit
+	return nil
+}
+
+// MarshalText encodes the rank as one of 9, J, Q, K, 10 or A.
+func (r Rank) MarshalText() ([]byte, error) {
+	str, ok := rankStrings[r]
+	if !ok {
+		return nil, fmt.Errorf("%w: rank %d", ErrInvalidCard, int(r))
+	}
+	return []byte(str), nil
+}
+
+// UnmarshalText decodes a rank encoded as one of 9, J, Q, K, 10 or A
+// (letters in any case).
+func (r *Rank) UnmarshalText(text []byte) error {
+	rank, ok := parseRank(string(text))
+	if !ok {
+		return fmt.Errorf("%w: rank %q", ErrInvalidCard, text)
+	}
+	*r = rank
+	return nil
+}
+
+// MarshalText encodes the card as its rank followed by the letter
+// of its suit, e.g. "10H" for the ten of hearts.
+func (c Card) MarshalText() ([]byte, error) {
+	rank, err := c.Rank.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	suit, err := c.Suit.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return append(rank, suit...), nil
+}
+
+// UnmarshalText decodes a card in any of the forms accepted by ParseCard.
+func (c *Card) UnmarshalText(text []byte) error {
+	card, err := ParseCard(string(text))
+	if err != nil {
+		return err
+	}
+	*c = card
+	return nil
+}
+
+// MarshalText encodes the player as "nobody", "ai" or "opponent".
+func (p Player) MarshalText() ([]byte, error) {
+	str, ok := playerStrings[p]
+	if !ok {
+		return nil, fmt.Errorf("invalid player %d", int(p))
+	}
+	return []byte(str), nil
+}
+
+// UnmarshalText decodes a player encoded as "nobody", "ai" or "opponent".
+func (p *Player) UnmarshalText(text []byte) error {
+	for player, str := range playerStrings {
+		if str == string(text) {
+			*p = player
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid player %q", text)
+}
+
+// MarshalJSON encodes the set as an array of cards
+// sorted by suit and rank (see Card.MarshalText).
+func (s CardSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Cards())
+}
+
+// UnmarshalJSON decodes a set from an array of cards.
+func (s *CardSet) UnmarshalJSON(data []byte) error {
+	var cards []Card
+	if err := json.Unmarshal(data, &cards); err != nil {
+		return err
+	}
+
+	var result CardSet
+	for _, card := range cards {
+		if result.Has(card) {
+			return fmt.Errorf("duplicate card %s", card)
+		}
+		result.Add(card)
+	}
+	*s = result
+	return nil
+}
+
+// MarshalJSON encodes the hand as an array of cards sorted
+// by suit and rank (see Card.MarshalText).
+func (h Hand) MarshalJSON() ([]byte, error) {
+	return h.cards.MarshalJSON()
+}
+
+// UnmarshalJSON decodes a hand from an array of cards.
+// An error is returned if there are more than 6 cards.
+func (h *Hand) UnmarshalJSON(data []byte) error {
+	var cards CardSet
+	if err := cards.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	if cards.Len() > 6 {
+		return errors.New("too many cards given")
+	}
+	*h = Hand{cards}
+	return nil
+}
+
+// MarshalJSON encodes the pile as an array of cards sorted
+// by suit and rank (see Card.MarshalText).
+func (p Pile) MarshalJSON() ([]byte, error) {
+	return p.cards.MarshalJSON()
+}
+
+// UnmarshalJSON decodes a pile from an array of cards.
+func (p *Pile) UnmarshalJSON(data []byte) error {
+	return p.cards.UnmarshalJSON(data)
+}
+
+// MarshalText encodes the hand as its cards sorted by suit and rank
+// and separated by spaces, e.g. "9D QD 10H AS".
+func (h Hand) MarshalText() ([]byte, error) {
+	return []byte(cardsNotation(h.cards)), nil
+}
+
+// UnmarshalText decodes a hand in any of the forms accepted by ParseHand.
+func (h *Hand) UnmarshalText(text []byte) error {
+	hand, err := ParseHand(string(text))
+	if err != nil {
+		return err
+	}
+	*h = hand
+	return nil
+}
+
+// MarshalText encodes the pile as its cards sorted by suit and rank
+// and separated by spaces, e.g. "9C JC QC".
+func (p Pile) MarshalText() ([]byte, error) {
+	return []byte(cardsNotation(p.cards)), nil
+}
+
+// UnmarshalText decodes a pile from cards (see ParseCard)
+// separated by spaces.
+func (p *Pile) UnmarshalText(text []byte) error {
+	var result CardSet
+	for _, field := range strings.Fields(string(text)) {
+		card, err := ParseCard(field)
+		if err != nil {
+			return err
+		}
+		if result.Has(card) {
+			return fmt.Errorf("duplicate card %s", card)
+		}
+		result.Add(card)
+	}
+	p.cards = result
+	return nil
+}
+
+// MarshalText encodes the move in the notation accepted by ParseMove,
+// e.g. "QH+20+switch" or "declare". The trump suit is not known, so an
+// announcement is written with the points of a marriage that is not
+// of trump in StandardRules.
+func (m Move) MarshalText() ([]byte, error) {
+	if !m.Declare || m.IsAnnouncement {
+		if _, err := m.Card.MarshalText(); err != nil {
+			return nil, err
+		}
+	}
+	return []byte(moveNotation(m, StandardRules.MarriagePoints)), nil
+}
+
+// UnmarshalText decodes a move in any of the forms accepted by ParseMove.
+func (m *Move) UnmarshalText(text []byte) error {
+	move, err := ParseMove(string(text))
+	if err != nil {
+		return err
+	}
+	*m = move
+	return nil
+}
+
+// moveJSON has the fields of Move without its methods, so that in JSON
+// a move stays an object instead of being encoded as text.
+type moveJSON Move
+
+// MarshalJSON encodes the move as an object (see Move).
+func (m Move) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moveJSON(m))
+}
+
+// UnmarshalJSON decodes a move from an object (see Move).
+func (m *Move) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, (*moveJSON)(m))
+}
@@ -0,0 +1,186 @@
+package santase
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCardJSON(t *testing.T) {
+	for _, card := range AllCards {
+		data, err := json.Marshal(card)
+		assert.Nil(t, err)
+
+		var decoded Card
+		assert.Nil(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, card, decoded)
+	}
+
+	data, err := json.Marshal(NewCard(Ten, Hearts))
+	assert.Nil(t, err)
+	assert.Equal(t, `"10H"`, string(data))
+
+	var card Card
+	assert.Nil(t, json.Unmarshal([]byte(`"Q♠"`), &card))
+	assert.Equal(t, NewCard(Queen, Spades), card)
+	assert.Nil(t, json.Unmarshal([]byte(`"as"`), &card))
+	assert.Equal(t, NewCard(Ace, Spades), card)
+
+	for _, invalid := range []string{`""`, `"H"`, `"1H"`, `"10X"`, `"QH "`, `3`} {
+		assert.NotNil(t, json.Unmarshal([]byte(invalid), &card), invalid)
+	}
+	err = json.Unmarshal([]byte(`"8H"`), &card)
+	assert.True(t, errors.Is(err, ErrInvalidCard))
+
+	_, err = json.Marshal(Card{Suit: 7, Rank: Ace})
+	assert.NotNil(t, err)
+}
+
+func TestSuitAndRankText(t *testing.T) {
+	text, err := Diamonds.MarshalText()
+	assert.Nil(t, err)
+	assert.Equal(t, "D", string(text))
+
+	text, err = Ten.MarshalText()
+	assert.Nil(t, err)
+	assert.Equal(t, "10", string(text))
+
+	var suit Suit
+	assert.Nil(t, suit.UnmarshalText([]byte("♥")))
+	assert.Equal(t, Hearts, suit)
+	assert.Nil(t, suit.UnmarshalText([]byte("c")))
+	assert.Equal(t, Clubs, suit)
+	assert.NotNil(t, suit.UnmarshalText([]byte("X")))
+
+	var rank Rank
+	assert.Nil(t, rank.UnmarshalText([]byte("k")))
+	assert.Equal(t, King, rank)
+	assert.NotNil(t, rank.UnmarshalText([]byte("8")))
+}
+
+func TestHandJSON(t *testing.T) {
+	hand := NewHand(
+		NewCard(Ace, Spades),
+		NewCard(Nine, Diamonds),
+		NewCard(Ten, Hearts),
+		NewCard(Queen, Diamonds),
+	)
+
+	data, err := json.Marshal(hand)
+	assert.Nil(t, err)
+	assert.Equal(t, `["9D","QD","10H","AS"]`, string(data))
+
+	var decoded Hand
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, hand, decoded)
+
+	assert.NotNil(t, json.Unmarshal([]byte(`["9D","9D"]`), &decoded))
+	assert.NotNil(t, json.Unmarshal([]byte(`["9C","JC","QC","KC","10C","AC","9D"]`), &decoded))
+}
+
+func TestPileJSON(t *testing.T) {
+	pile := NewPile()
+	for _, card := range AllCards[:8] {
+		pile.AddCard(card)
+	}
+
+	data, err := json.Marshal(pile)
+	assert.Nil(t, err)
+	assert.Equal(t, `["9C","JC","QC","KC","10C","AC","9D","JD"]`, string(data))
+
+	var decoded Pile
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, pile, decoded)
+
+	data, err = json.Marshal(NewPile())
+	assert.Nil(t, err)
+	assert.Equal(t, `[]`, string(data))
+}
+
+func TestMoveJSON(t *testing.T) {
+	move := Move{Card: NewCard(Queen, Hearts), IsAnnouncement: true, SwitchTrumpCard: true}
+
+	data, err := json.Marshal(move)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"card":"QH","isAnnouncement":true,"switchTrumpCard":true}`, string(data))
+
+	var decoded Move
+	assert.Nil(t, json.Unmarshal([]byte(`{"card":"K♦","closeGame":true}`), &decoded))
+	assert.Equal(t, Move{Card: NewCard(King, Diamonds), CloseGame: true}, decoded)
+}
+
+func TestHandText(t *testing.T) {
+	hand := NewHand(
+		NewCard(Ace, Spades),
+		NewCard(Nine, Diamonds),
+		NewCard(Ten, Hearts),
+		NewCard(Queen, Diamonds),
+	)
+
+	text, err := hand.MarshalText()
+	assert.Nil(t, err)
+	assert.Equal(t, "9D QD 10H AS", string(text))
+
+	var decoded Hand
+	assert.Nil(t, decoded.UnmarshalText(text))
+	assert.Equal(t, hand, decoded)
+
+	text, err = NewHand().MarshalText()
+	assert.Nil(t, err)
+	assert.Equal(t, "", string(text))
+	assert.Nil(t, decoded.UnmarshalText(text))
+	assert.Equal(t, NewHand(), decoded)
+
+	assert.NotNil(t, decoded.UnmarshalText([]byte("9D 9D")))
+	assert.NotNil(t, decoded.UnmarshalText([]byte("9C JC QC KC 10C AC 9D")))
+	assert.NotNil(t, decoded.UnmarshalText([]byte("9D 8D")))
+}
+
+func TestPileText(t *testing.T) {
+	pile := NewPile()
+	for _, card := range AllCards[:8] {
+		pile.AddCard(card)
+	}
+
+	text, err := pile.MarshalText()
+	assert.Nil(t, err)
+	assert.Equal(t, "9C JC QC KC 10C AC 9D JD", string(text))
+
+	var decoded Pile
+	assert.Nil(t, decoded.UnmarshalText(text))
+	assert.Equal(t, pile, decoded)
+
+	assert.NotNil(t, decoded.UnmarshalText([]byte("9D 9D")))
+	assert.NotNil(t, decoded.UnmarshalText([]byte("9D 8D")))
+}
+
+func TestMoveText(t *testing.T) {
+	moves := []Move{
+		{Card: NewCard(Ten, Hearts)},
+		{Card: NewCard(Queen, Hearts), IsAnnouncement: true},
+		{Card: NewCard(Nine, Clubs), SwitchTrumpCard: true, CloseGame: true},
+		{Card: NewCard(King, Spades), IsAnnouncement: true, Declare: true},
+		{Declare: true},
+	}
+	for _, move := range moves {
+		text, err := move.MarshalText()
+		assert.Nil(t, err)
+
+		var decoded Move
+		assert.Nil(t, decoded.UnmarshalText(text), string(text))
+		assert.Equal(t, move, decoded, string(text))
+	}
+
+	text, err := Move{Card: NewCard(Queen, Hearts), IsAnnouncement: true, SwitchTrumpCard: true}.MarshalText()
+	assert.Nil(t, err)
+	assert.Equal(t, "QH+20+switch", string(text))
+
+	_, err = Move{Card: Card{Suit: 7, Rank: Ace}}.MarshalText()
+	assert.NotNil(t, err)
+
+	var move Move
+	assert.NotNil(t, move.UnmarshalText([]byte("QH+30")))
+	assert.NotNil(t, move.UnmarshalText([]byte("10H+declare")))
+}
@@ -0,0 +1,124 @@
+package santase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors returned by the Try* methods of Game when the requested
+// action violates the rules of the game or is done out of order.
+// The panicking methods of Game panic with the message of these errors.
+var (
+	ErrGameOver        = errors.New("the game is over")
+	ErrNotAITurn       = errors.New("not AI's turn")
+	ErrNotOpponentTurn = errors.New("not opponent's turn")
+	ErrDrawPending     = errors.New("should not play before drawing cards")
+
+	ErrCardNotInHand     = errors.New("played card is not in hand")
+	ErrCardAlreadyPlayed = errors.New("card has already been played")
+	ErrCardInAIHand      = errors.New("card is in ai's hand")
+	ErrCardOnTable       = errors.New("card is the same as the one on the table")
+	ErrCardIsTrumpCard   = errors.New("played card is the trump card")
+
+	ErrSwitchNotFirst     = errors.New("cannot switch trump card when you're not first to play")
+	ErrSwitchOnFirstMove  = errors.New("cannot switch trump card on first move")
+	ErrSwitchTwoCardsLeft = errors.New("cannot switch trump card with only two cards left in the stack")
+	ErrSwitchTrumpTaken   = errors.New("cannot switch trump card after it has been taken")
+	ErrSwitchClosed       = errors.New("cannot switch trump card after the game has been closed")
+	ErrSwitchWithoutNine  = errors.New("cannot switch trump card without nine of trump in hand")
+	ErrSwitchTrumpIsNine  = errors.New("cannot switch trump card - trump card is a nine")
+
+	ErrCloseNotFirst     = errors.New("cannot close game when second to move")
+	ErrCloseOnFirstMove  = errors.New("cannot close game on first move")
+	ErrCloseTwoCardsLeft = errors.New("cannot close game with only two cards left in the stack")
+	ErrCloseAllDrawn     = errors.New("cannot close game after all cards have been drawn")
+	ErrAlreadyClosed     = errors.New("cannot close game because it is already closed")
+
+	ErrAnnounceNotFirst           = errors.New("cannot announce when you're not first to play")
+	ErrAnnounceOnFirstMove        = errors.New("cannot announce on first move")
+	ErrInvalidAnnouncementCard    = errors.New("invalid announcement card")
+	ErrMarriageNotInHand          = errors.New("invalid announcement - not both cards of announcement are in hand")
+	ErrMarriagePartnerPlayed      = errors.New("cannot be an announcement because other card has already been played")
+	ErrMarriagePartnerInAIHand    = errors.New("cannot be an announcement because other card is in ai's hand")
+	ErrMarriagePartnerIsTrumpCard = errors.New("cannot be an announcement because other card is the trump card")
+
+	ErrDeclareNotFirst    = errors.New("cannot declare when you're not first to play")
+	ErrInvalidDeclaration = errors.New("cannot switch trump card or close the game when declaring")
+
+	ErrMatchOver      = errors.New("the match is over")
+	ErrDealInProgress = errors.New("the current deal is not over")
+
+	ErrDrawMidTrick            = errors.New("cannot draw cards in the middle of a play")
+	ErrDrawClosed              = errors.New("should not draw cards when the game is closed")
+	ErrDrawBeforeFirstPlay     = errors.New("should not draw cards before the first play")
+	ErrDrawTwice               = errors.New("should not draw cards twice before playing")
+	ErrDrawnCardPlayed         = errors.New("drawn card has been played before")
+	ErrDrawnCardInOpponentHand = errors.New("cannot draw card that is in opponent's hand")
+	ErrDrawnCardInHand         = errors.New("cannot draw card that is in the hand already")
+	ErrAllCardsDrawn           = errors.New("all cards are drawn already")
+	ErrDrawTrumpCardEarly      = errors.New("cannot draw trump card yet")
+)
+
+// IllegalResponseError is returned when a card is played in response
+// to Played, but the game is closed (or all cards have been drawn) and
+// the rules force the player to respond with one of the Legal cards.
+// IsClosed tells which of the two is the case.
+type IllegalResponseError struct {
+	Card     Card
+	Played   Card
+	Legal    Hand
+	IsClosed bool
+}
+
+func (e *IllegalResponseError) Error() string {
+	legal := e.Legal.cards
+	rule := "must play a trump"
+	if legal.OfSuit(e.Played.Suit) == legal {
+		rule = "must follow suit"
+		if legal&^(e.Played.bit()<<1-1) == legal {
+			rule = "must follow suit with a higher card"
+		}
+	}
+
+	reason := "all cards have been drawn"
+	if e.IsClosed {
+		reason = "the game is closed"
+	}
+
+	return fmt.Sprintf("invalid response card: %s - %s because %s", e.Card, rule, reason)
+}
+
+// MoveError is returned by ValidateMove and ValidateOpponentMove when
+// a move is not legal. Err is the rule the move violates (one of the
+// errors above or an *IllegalResponseError) and Legal are the moves
+// that can be played instead (see LegalMoves).
+type MoveError struct {
+	Move  Move
+	Err   error
+	Legal []Move
+
+	rules Rules
+	trump Suit
+}
+
+func (e *MoveError) Error() string {
+	message := fmt.Sprintf("illegal move %s: %v", moveNotation(e.Move, e.rules.AnnouncementPoints(e.Move.Card, e.trump)), e.Err)
+	if len(e.Legal) == 0 {
+		return message
+	}
+
+	legal := make([]string, len(e.Legal))
+	for i, move := range e.Legal {
+		legal[i] = moveNotation(move, e.rules.AnnouncementPoints(move.Card, e.trump))
+	}
+	return message + " (legal moves: " + strings.Join(legal, ", ") + ")"
+}
+
+func (e *MoveError) Unwrap() error {
+	return e.Err
+}
+
+func invalidAnnouncementCard(card Card) error {
+	return fmt.Errorf("%w: %s", ErrInvalidAnnouncementCard, card)
+}
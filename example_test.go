@@ -54,12 +54,12 @@ func ExampleNewHand() {
 		santase.NewCard(santase.King, santase.Hearts),
 	)
 
-	// the cards in a hand can be iterated like so
-	for card := range hand {
+	// the cards in a hand can be iterated in order like so
+	for _, card := range hand.ToSlice() {
 		fmt.Println(card)
 	}
 
-	// Unordered output:
+	// Output:
 	// Q♥
 	// K♥
 }
@@ -92,12 +92,12 @@ func ExampleNewPile() {
 	pile.AddCard(santase.NewCard(santase.Queen, santase.Hearts))
 	pile.AddCard(santase.NewCard(santase.King, santase.Hearts))
 
-	// the cards in a pile can be iterated like so
-	for card := range pile {
+	// the cards in a pile can be iterated in order like so
+	for _, card := range pile.ToSlice() {
 		fmt.Println(card)
 	}
 
-	// Unordered output:
+	// Output:
 	// Q♥
 	// K♥
 }
@@ -1,5 +1,7 @@
 package santase
 
+import "context"
+
 type dummyAgent struct{}
 
 func (a dummyAgent) GetMove(g *Game) Move {
@@ -13,6 +15,8 @@ type Game struct {
 	trump              Suit
 	score              int
 	opponentScore      int
+	pendingScore       int
+	opponentPending    int
 	hand               Hand
 	knownOpponentCards Hand
 	seenCards          Pile
@@ -21,17 +25,35 @@ type Game struct {
 	cardPlayed         *Card
 	isOpponentMove     bool
 	isClosed           bool
+	tricks             int
+	opponentTricks     int
+	closedBy           Player
+	tricksWhenClosed   int
+	declaredBy         Player
+	rules              Rules
+	history            []Event
+	undo               []GameState
+	initialHand        Hand
+	initialTrumpCard   Card
 	agent              Agent
 }
 
 // CreateGame creates a new instance of a Game given the
 // initial hand for the AI, the trump card on the table and
 // whether the opponent (from the point of view of the AI)
-// plays first.
+// plays first. The game is played with the StandardRules.
 //
 // Panics if the hand does not have 6 cards.
 func CreateGame(hand Hand, trumpCard Card, isOpponentMove bool) Game {
-	if len(hand) != 6 {
+	return CreateGameWithRules(hand, trumpCard, isOpponentMove, StandardRules)
+}
+
+// CreateGameWithRules creates a new instance of a Game like
+// CreateGame, but played with the given rules.
+//
+// Panics if the hand does not have 6 cards.
+func CreateGameWithRules(hand Hand, trumpCard Card, isOpponentMove bool, rules Rules) Game {
+	if hand.Len() != 6 {
 		panic("player's hand is not complete")
 	}
 
@@ -39,6 +61,8 @@ func CreateGame(hand Hand, trumpCard Card, isOpponentMove bool) Game {
 		trump:              trumpCard.Suit,
 		score:              0,
 		opponentScore:      0,
+		pendingScore:       0,
+		opponentPending:    0,
 		hand:               hand,
 		knownOpponentCards: NewHand(),
 		seenCards:          NewPile(),
@@ -47,6 +71,14 @@ func CreateGame(hand Hand, trumpCard Card, isOpponentMove bool) Game {
 		cardPlayed:         nil,
 		isOpponentMove:     isOpponentMove,
 		isClosed:           false,
+		tricks:             0,
+		opponentTricks:     0,
+		closedBy:           Nobody,
+		tricksWhenClosed:   0,
+		declaredBy:         Nobody,
+		rules:              rules,
+		initialHand:        hand.Clone(),
+		initialTrumpCard:   trumpCard,
 		agent:              dummyAgent{},
 	}
 }
@@ -96,6 +128,21 @@ func (g *Game) GetOpponentScore() int {
 	return g.opponentScore
 }
 
+// GetPendingScore returns the points of the announcements of the AI
+// player that do not count yet, because the AI has not won a trick
+// (see Rules.DeferAnnouncements). They are added to the score when
+// the AI wins its first trick.
+func (g *Game) GetPendingScore() int {
+	return g.pendingScore
+}
+
+// GetOpponentPendingScore returns the points of the announcements of
+// the opponent that do not count yet, because the opponent has not
+// won a trick (see Rules.DeferAnnouncements).
+func (g *Game) GetOpponentPendingScore() int {
+	return g.opponentPending
+}
+
 // GetTrump returns the trump suit of the game.
 func (g *Game) GetTrump() Suit {
 	return g.trump
@@ -124,6 +171,56 @@ func (g *Game) GetUnseenCards() Pile {
 	return g.unseenCards.Clone()
 }
 
+// GetRules returns the rules the game is played with.
+func (g *Game) GetRules() Rules {
+	return g.rules
+}
+
+// GetTally returns the information about the game that is needed
+// to determine its result.
+func (g *Game) GetTally() Tally {
+	lastTrickWinner := AI
+	if g.isOpponentMove {
+		lastTrickWinner = Opponent
+	}
+
+	return Tally{
+		TargetScore:      g.rules.TargetScore,
+		Score:            g.score,
+		OpponentScore:    g.opponentScore,
+		Tricks:           g.tricks,
+		OpponentTricks:   g.opponentTricks,
+		ClosedBy:         g.closedBy,
+		TricksWhenClosed: g.tricksWhenClosed,
+		DeclaredBy:       g.declaredBy,
+		IsPlayedOut:      g.hand.Len() == 0 && g.cardPlayed == nil,
+		LastTrickWinner:  lastTrickWinner,
+	}
+}
+
+// IsOver returns if the game has ended. After that no more
+// moves can be played.
+//
+// See Tally.Result for the rules that determine when a game ends.
+func (g *Game) IsOver() bool {
+	winner, _ := g.GetTally().Result()
+	return winner != Nobody
+}
+
+// Winner returns the player that won the game or Nobody if
+// the game is not over yet.
+func (g *Game) Winner() Player {
+	winner, _ := g.GetTally().Result()
+	return winner
+}
+
+// GamePoints returns the game points (1, 2 or 3) the winner
+// of the game earns or 0 if the game is not over yet.
+func (g *Game) GamePoints() int {
+	_, points := g.GetTally().Result()
+	return points
+}
+
 // SetAgent sets the Agent that will be used to choose the moves that
 // will be played by the AI. There is a random agent and a monte carlo
 // agent included in the library that can be used, or you can write your own.
@@ -137,118 +234,225 @@ func (g *Game) StrongerCard(first *Card, second *Card) *Card {
 	return StrongerCard(first, second, g.trump)
 }
 
+// announce adds the points of the announcement of the marriage the
+// passed card is part of to the score of the announcing player. If the
+// announcements are deferred and the player has not won a trick yet the
+// points are pending instead.
+func (g *Game) announce(player Player, card Card) {
+	points := g.rules.AnnouncementPoints(card, g.trump)
+	g.record(Event{Kind: EventAnnouncement, Player: player, Card: card, Points: points})
+
+	switch {
+	case player == AI && g.rules.DeferAnnouncements && g.tricks == 0:
+		g.pendingScore += points
+	case player == AI:
+		g.score += points
+	case g.rules.DeferAnnouncements && g.opponentTricks == 0:
+		g.opponentPending += points
+	default:
+		g.opponentScore += points
+	}
+}
+
+// completeTrick finishes the trick with the response to the card
+// played and gives it to the player that played the stronger card.
+func (g *Game) completeTrick(response Card) {
+	leader := AI
+	if !g.isOpponentMove {
+		leader = Opponent
+	}
+
+	winner := leader
+	if StrongerCard(g.cardPlayed, &response, g.trump) == &response {
+		winner = leader.Other()
+	}
+
+	points := Points(g.cardPlayed) + Points(&response) + g.lastTrickBonus()
+	g.winTrick(winner, points)
+	g.record(Event{
+		Kind:     EventTrick,
+		Player:   winner,
+		Leader:   leader,
+		Lead:     *g.cardPlayed,
+		Response: response,
+		Points:   points,
+	})
+
+	g.seenCards.AddCard(*g.cardPlayed)
+	g.seenCards.AddCard(response)
+	g.cardPlayed = nil
+}
+
+// winTrick gives the trick that has just been played to the passed
+// player, who plays first in the next trick. The pending points of the
+// player count from now on.
+func (g *Game) winTrick(player Player, points int) {
+	if player == AI {
+		g.score += points + g.pendingScore
+		g.pendingScore = 0
+		g.tricks++
+		g.isOpponentMove = false
+	} else {
+		g.opponentScore += points + g.opponentPending
+		g.opponentPending = 0
+		g.opponentTricks++
+		g.isOpponentMove = true
+	}
+}
+
+// lastTrickBonus returns the extra points the winner of the trick being
+// played earns. Only the last trick of a game that was not closed brings
+// the bonus, the hand of the AI is empty when it is played.
+func (g *Game) lastTrickBonus() int {
+	if g.hand.Len() == 0 && !g.isClosed {
+		return g.rules.LastTrickBonus
+	}
+	return 0
+}
+
+// IsDrawPending returns if a trick has been completed and the card
+// drawn by the AI has not been given with UpdateDrawnCard yet.
+func (g *Game) IsDrawPending() bool {
+	return g.isDrawPending()
+}
+
+func (g *Game) isDrawPending() bool {
+	return !g.isClosed && g.cardPlayed == nil && g.seenCards.Len() <= 12 && g.hand.Len() != 6
+}
+
 // GetMove returns the move that the AI agent chose to play. It should be
 // called only when it is the AI's turn to play, otherwise a panic will occur.
 // If there is a bug in the agent and it chooses an invalid move a panic will
 // occur as well.
 //
+// GetMove is a wrapper around TryGetMove that panics with the message of
+// the returned error.
+//
 // Note: the order of calls to GetMove, UpdateOpponentMove and
 // UpdateDrawnCard matters.
 func (g *Game) GetMove() Move {
-	if g.isOpponentMove {
-		panic("not AI's turn")
-	}
+	return g.GetMoveContext(context.Background())
+}
 
-	if !g.isClosed && g.cardPlayed == nil && len(g.seenCards) <= 12 && len(g.hand) != 6 {
-		panic("should not play before drawing cards")
+// GetMoveContext is like GetMove, but the search of the agent for a
+// move is stopped when ctx is done if the agent is a ContextAgent. In
+// that case the agent plays the best move it has found so far.
+//
+// GetMoveContext is a wrapper around TryGetMoveContext that panics with
+// the message of the returned error.
+func (g *Game) GetMoveContext(ctx context.Context) Move {
+	move, err := g.TryGetMoveContext(ctx)
+	if err != nil {
+		panic(err.Error())
 	}
+	return move
+}
 
-	move := g.agent.GetMove(g)
-
-	if move.SwitchTrumpCard {
-		if g.cardPlayed != nil {
-			panic("cannot switch trump card when you're not first to play")
-		}
-
-		if len(g.seenCards) == 0 {
-			panic("cannot switch trump card on first move")
-		}
-
-		if len(g.seenCards) == 10 {
-			panic("cannot switch trump card with only two cards left in the stack")
-		}
-
-		if g.trumpCard == nil {
-			panic("cannot switch trump card after it has been taken")
-		}
-
-		if g.isClosed {
-			panic("cannot switch trump card after the game has been closed")
-		}
+// TryGetMove returns the move that the AI agent chose to play. If it is not
+// the AI's turn to play or the agent chooses an invalid move an error is
+// returned and the game state is left unchanged.
+//
+// Note: the order of calls to TryGetMove, TryUpdateOpponentMove and
+// TryUpdateDrawnCard matters.
+func (g *Game) TryGetMove() (Move, error) {
+	return g.TryGetMoveContext(context.Background())
+}
 
-		if !g.hand.HasCard(NewCard(Nine, g.trump)) {
-			panic("cannot switch trump card withouth nine of trump in hand")
-		}
+// TryGetMoveContext is like TryGetMove, but the search of the agent for
+// a move is stopped when ctx is done if the agent is a ContextAgent. In
+// that case the agent plays the best move it has found so far, which is
+// validated like any other move.
+func (g *Game) TryGetMoveContext(ctx context.Context) (Move, error) {
+	if g.IsOver() {
+		return Move{}, ErrGameOver
+	}
 
-		g.hand.RemoveCard(NewCard(Nine, g.trump))
-		g.hand.AddCard(*g.trumpCard)
-		g.trumpCard.Rank = Nine
+	if g.isOpponentMove {
+		return Move{}, ErrNotAITurn
 	}
 
-	if move.CloseGame {
-		if g.cardPlayed != nil {
-			panic("cannot close game when second to move")
-		}
+	if g.isDrawPending() {
+		return Move{}, ErrDrawPending
+	}
 
-		if len(g.seenCards) == 0 {
-			panic("cannot close game on first move")
-		}
+	var move Move
+	if agent, ok := g.agent.(ContextAgent); ok {
+		move = agent.GetMoveContext(ctx, g)
+	} else {
+		move = g.agent.GetMove(g)
+	}
+	if err := g.validateMove(move); err != nil {
+		return Move{}, err
+	}
 
-		if len(g.seenCards) == 10 {
-			panic("cannot close game with only two cards left in the stack")
-		}
+	g.saveUndo()
+	g.applyMove(move)
+	return move, nil
+}
 
-		if len(g.seenCards) >= 12 {
-			panic("cannot close game after all cards have been drawn")
-		}
+func (g *Game) validateMove(move Move) error {
+	if g.IsOver() {
+		return ErrGameOver
+	}
 
-		if g.isClosed {
-			panic("cannot close game because it is already closed")
-		}
+	if g.isOpponentMove {
+		return ErrNotAITurn
+	}
 
-		g.isClosed = true
+	if g.isDrawPending() {
+		return ErrDrawPending
 	}
 
-	if move.IsAnnouncement {
-		if g.cardPlayed != nil {
-			panic("cannot announce when you're not first to play")
-		}
+	return g.position(g.hand).validateMove(move)
+}
 
-		if len(g.seenCards) == 0 {
-			panic("cannot announce on first move")
-		}
+// position returns the position of the game
+// for the player to move, who may hold hand.
+func (g *Game) position(hand Hand) position {
+	return position{
+		rules:      g.rules,
+		trump:      g.trump,
+		trumpCard:  g.trumpCard,
+		cardPlayed: g.cardPlayed,
+		isClosed:   g.isClosed,
+		tricks:     g.seenCards.Len() / 2,
+		hand:       hand,
+	}
+}
 
-		if move.Card.Rank != Queen && move.Card.Rank != King {
-			panic("invalid announcement card")
-		}
+// possibleOpponentCards returns the cards the opponent may hold.
+func (g *Game) possibleOpponentCards() Hand {
+	return Hand{g.knownOpponentCards.cards.Union(g.unseenCards.cards)}
+}
 
-		var other Card
-		if move.Card.Rank == Queen {
-			other = NewCard(King, move.Card.Suit)
-		} else {
-			other = NewCard(Queen, move.Card.Suit)
-		}
+func (g *Game) applyMove(move Move) {
+	g.record(Event{Kind: EventMove, Player: AI, Move: move})
 
-		if !g.hand.HasCard(other) {
-			panic("invalid announcement - not both cards of announcement are in hand")
+	if move.Declare {
+		if move.IsAnnouncement {
+			g.announce(AI, move.Card)
 		}
+		g.declaredBy = AI
+		g.record(Event{Kind: EventDeclaration, Player: AI})
+		return
+	}
 
-		if move.Card.Suit == g.trump {
-			g.score += 40
-		} else {
-			g.score += 20
-		}
+	if move.SwitchTrumpCard {
+		g.record(Event{Kind: EventSwitch, Player: AI, Card: *g.trumpCard})
+		g.hand.RemoveCard(NewCard(Nine, g.trump))
+		g.hand.AddCard(*g.trumpCard)
+		g.trumpCard.Rank = Nine
 	}
 
-	if !g.hand.HasCard(move.Card) {
-		panic("played card in not in hand")
+	if move.CloseGame {
+		g.isClosed = true
+		g.closedBy = AI
+		g.tricksWhenClosed = g.opponentTricks
+		g.record(Event{Kind: EventClose, Player: AI})
 	}
 
-	if g.cardPlayed != nil && (g.isClosed || g.trumpCard == nil) {
-		possibleResponses := g.hand.GetValidResponses(*g.cardPlayed, g.trump)
-		if !possibleResponses.HasCard(move.Card) {
-			panic("invalid response card: " + move.Card.String())
-		}
+	if move.IsAnnouncement {
+		g.announce(AI, move.Card)
 	}
 
 	g.hand.RemoveCard(move.Card)
@@ -257,147 +461,223 @@ func (g *Game) GetMove() Move {
 		g.cardPlayed = &move.Card
 		g.isOpponentMove = true
 	} else {
-		stronger := StrongerCard(g.cardPlayed, &move.Card, g.trump)
-		if g.cardPlayed == stronger {
-			g.opponentScore += Points(g.cardPlayed) + Points(&move.Card)
-			g.isOpponentMove = true
-		} else {
-			g.score += Points(g.cardPlayed) + Points(&move.Card)
-			g.isOpponentMove = false
-		}
-		g.seenCards.AddCard(*g.cardPlayed)
-		g.seenCards.AddCard(move.Card)
-		g.cardPlayed = nil
+		g.completeTrick(move.Card)
 	}
-
-	return move
 }
 
 // UpdateOpponentMove updates the game state with the move that the opponent
 // has played. If the move is invalid a panic will occur.
 //
+// UpdateOpponentMove is a wrapper around TryUpdateOpponentMove that panics
+// with the message of the returned error.
+//
 // Note: the order of calls to GetMove, UpdateOpponentMove and
 // UpdateDrawnCard matters.
 func (g *Game) UpdateOpponentMove(opponentMove Move) {
+	if err := g.TryUpdateOpponentMove(opponentMove); err != nil {
+		panic(err.Error())
+	}
+}
+
+// TryUpdateOpponentMove updates the game state with the move that the
+// opponent has played. If the move is invalid an error is returned and
+// the game state is left unchanged.
+//
+// Note: the order of calls to TryGetMove, TryUpdateOpponentMove and
+// TryUpdateDrawnCard matters.
+func (g *Game) TryUpdateOpponentMove(opponentMove Move) error {
+	if err := g.validateOpponentMove(opponentMove); err != nil {
+		return err
+	}
+
+	g.saveUndo()
+	g.applyOpponentMove(opponentMove)
+	return nil
+}
+
+func (g *Game) validateOpponentMove(opponentMove Move) error {
+	if g.IsOver() {
+		return ErrGameOver
+	}
+
 	if !g.isOpponentMove {
-		panic("not opponent's turn")
+		return ErrNotOpponentTurn
+	}
+
+	if opponentMove.Declare {
+		return g.validateOpponentDeclaration(opponentMove)
 	}
 
 	if g.seenCards.HasCard(opponentMove.Card) {
-		panic("card has already been played")
+		return ErrCardAlreadyPlayed
 	}
 
 	if g.hand.HasCard(opponentMove.Card) {
-		panic("card is in ai's hand")
+		return ErrCardInAIHand
 	}
 
 	if g.cardPlayed != nil && *g.cardPlayed == opponentMove.Card {
-		panic("card is the same as the one on the table")
+		return ErrCardOnTable
 	}
 
-	if !g.isClosed && g.cardPlayed == nil && len(g.seenCards) <= 12 && len(g.hand) != 6 {
-		panic("should not play before drawing cards")
+	if g.isDrawPending() {
+		return ErrDrawPending
 	}
 
+	p := g.position(g.possibleOpponentCards())
+	hand := p.hand
+	trumpCard := g.trumpCard
 	if opponentMove.SwitchTrumpCard {
-		if g.cardPlayed != nil {
-			panic("cannot switch trump card when you're not first to play")
-		}
-
-		if len(g.seenCards) == 0 {
-			panic("cannot switch trump card on first move")
+		if err := p.validateSwitch(); err != nil {
+			return err
 		}
 
-		if len(g.seenCards) == 10 {
-			panic("cannot switch trump card with only two cards left in the stack")
-		}
+		hand = p.switchedHand()
+		nineTrump := NewCard(Nine, g.trump)
+		trumpCard = &nineTrump
+	}
 
-		if g.trumpCard == nil {
-			panic("cannot switch trump card after it has been taken")
+	if opponentMove.CloseGame {
+		if err := p.validateClose(); err != nil {
+			return err
 		}
+	}
 
-		if g.isClosed {
-			panic("cannot switch trump card after the game has been closed")
-		}
+	if trumpCard != nil && opponentMove.Card == *trumpCard {
+		return ErrCardIsTrumpCard
+	}
 
-		if g.trumpCard.Rank == Nine {
-			panic("cannot switch trump card - trump card is a nine")
+	if opponentMove.IsAnnouncement {
+		if err := g.validateOpponentAnnouncement(p, opponentMove.Card, hand); err != nil {
+			return err
 		}
-
-		g.knownOpponentCards.AddCard(*g.trumpCard)
-		g.trumpCard.Rank = Nine
-		g.knownOpponentCards.RemoveCard(*g.trumpCard)
-		g.unseenCards.RemoveCard(*g.trumpCard)
 	}
 
-	if opponentMove.CloseGame {
-		if g.cardPlayed != nil {
-			panic("cannot close game when second to move")
-		}
+	// once the hand of the opponent is known, e.g. after the trump card
+	// is taken, their move is checked like a move of the AI, including
+	// the cards they have to respond with
+	if hand, ok := g.knownOpponentHand(); ok {
+		return g.position(hand).validateMove(opponentMove)
+	}
 
-		if len(g.seenCards) == 0 {
-			panic("cannot close game on first move")
-		}
+	return nil
+}
 
-		if len(g.seenCards) == 10 {
-			panic("cannot close game with only two cards left in the stack")
-		}
+// opponentHandSize returns the number of cards in the hand of the
+// opponent. The player that played the card on the table has one
+// card less than the other player.
+func (g *Game) opponentHandSize() int {
+	size := g.hand.Len()
+	if g.cardPlayed != nil && g.isOpponentMove {
+		size++
+	} else if g.cardPlayed != nil {
+		size--
+	}
+	return size
+}
 
-		if len(g.seenCards) >= 12 {
-			panic("cannot close game after all cards have been drawn")
-		}
+// knownOpponentHand returns the hand of the opponent
+// and whether all of its cards are known to the AI.
+func (g *Game) knownOpponentHand() (Hand, bool) {
+	return g.knownOpponentCards, g.knownOpponentCards.Len() == g.opponentHandSize()
+}
 
-		if g.isClosed {
-			panic("cannot close game because it is already closed")
-		}
+// validateOpponentAnnouncement checks an announcement of the opponent,
+// who may hold any of the cards in hand. If the other card of the
+// marriage cannot be in their hand, the error tells where it is.
+func (g *Game) validateOpponentAnnouncement(p position, card Card, hand Hand) error {
+	err := p.validateAnnouncement(card, hand)
+	if err != ErrMarriageNotInHand {
+		return err
+	}
 
-		g.isClosed = true
+	other := marriagePartner(card)
+	switch {
+	case g.seenCards.HasCard(other):
+		return ErrMarriagePartnerPlayed
+	case g.hand.HasCard(other):
+		return ErrMarriagePartnerInAIHand
+	default:
+		return ErrMarriagePartnerIsTrumpCard
 	}
+}
 
-	if g.trumpCard != nil && opponentMove.Card == *g.trumpCard {
-		panic("played card is the trump card")
+// validateOpponentDeclaration checks a move declaring that the opponent
+// has collected enough points to win. The declaration can be made together
+// with an announcement, in which case the announced card has to be one the
+// opponent may hold.
+func (g *Game) validateOpponentDeclaration(opponentMove Move) error {
+	if g.cardPlayed != nil {
+		return ErrDeclareNotFirst
 	}
 
-	g.knownOpponentCards.RemoveCard(opponentMove.Card)
+	if opponentMove.SwitchTrumpCard || opponentMove.CloseGame {
+		return ErrInvalidDeclaration
+	}
 
 	if opponentMove.IsAnnouncement {
-		if g.cardPlayed != nil {
-			panic("cannot announce when you're not first to play")
+		if g.seenCards.HasCard(opponentMove.Card) {
+			return ErrCardAlreadyPlayed
 		}
 
-		if len(g.seenCards) == 0 {
-			panic("cannot announce on first move")
+		if g.hand.HasCard(opponentMove.Card) {
+			return ErrCardInAIHand
 		}
 
-		if opponentMove.Card.Rank != Queen && opponentMove.Card.Rank != King {
-			panic("invalid announcement card: " + opponentMove.Card.String())
+		if g.trumpCard != nil && opponentMove.Card == *g.trumpCard {
+			return ErrCardIsTrumpCard
 		}
 
-		var other Card
-		if opponentMove.Card.Rank == Queen {
-			other = NewCard(King, opponentMove.Card.Suit)
-		} else {
-			other = NewCard(Queen, opponentMove.Card.Suit)
+		p := g.position(g.possibleOpponentCards())
+		if err := g.validateOpponentAnnouncement(p, opponentMove.Card, p.hand); err != nil {
+			return err
 		}
+	}
 
-		if g.seenCards.HasCard(other) {
-			panic("cannot be an announcement because other card has already been played")
-		}
+	return nil
+}
 
-		if g.hand.HasCard(other) {
-			panic("cannot be an announcement because other card is in ai's hand")
-		}
+func (g *Game) applyOpponentMove(opponentMove Move) {
+	g.record(Event{Kind: EventMove, Player: Opponent, Move: opponentMove})
 
-		if g.trumpCard != nil && other == *g.trumpCard {
-			panic("cannot be an announcement because other card is the trump card")
-		}
+	if opponentMove.Declare {
+		if opponentMove.IsAnnouncement {
+			g.announce(Opponent, opponentMove.Card)
 
-		if opponentMove.Card.Suit == g.trump {
-			g.opponentScore += 40
-		} else {
-			g.opponentScore += 20
+			// the cards may be known already, e.g. once all cards are drawn
+			for _, card := range []Card{opponentMove.Card, marriagePartner(opponentMove.Card)} {
+				if !g.knownOpponentCards.HasCard(card) {
+					g.knownOpponentCards.AddCard(card)
+					g.unseenCards.RemoveCard(card)
+				}
+			}
 		}
+		g.declaredBy = Opponent
+		g.record(Event{Kind: EventDeclaration, Player: Opponent})
+		return
+	}
+
+	if opponentMove.SwitchTrumpCard {
+		g.record(Event{Kind: EventSwitch, Player: Opponent, Card: *g.trumpCard})
+		g.knownOpponentCards.AddCard(*g.trumpCard)
+		g.trumpCard.Rank = Nine
+		g.knownOpponentCards.RemoveCard(*g.trumpCard)
+		g.unseenCards.RemoveCard(*g.trumpCard)
+	}
 
+	if opponentMove.CloseGame {
+		g.isClosed = true
+		g.closedBy = Opponent
+		g.tricksWhenClosed = g.tricks
+		g.record(Event{Kind: EventClose, Player: Opponent})
+	}
+
+	g.knownOpponentCards.RemoveCard(opponentMove.Card)
+
+	if opponentMove.IsAnnouncement {
+		g.announce(Opponent, opponentMove.Card)
+
+		other := marriagePartner(opponentMove.Card)
 		g.knownOpponentCards.AddCard(other)
 		g.unseenCards.RemoveCard(other)
 	}
@@ -408,17 +688,7 @@ func (g *Game) UpdateOpponentMove(opponentMove Move) {
 		g.cardPlayed = &opponentMove.Card
 		g.isOpponentMove = false
 	} else {
-		stronger := StrongerCard(g.cardPlayed, &opponentMove.Card, g.trump)
-		if g.cardPlayed == stronger {
-			g.score += Points(g.cardPlayed) + Points(&opponentMove.Card)
-			g.isOpponentMove = false
-		} else {
-			g.opponentScore += Points(g.cardPlayed) + Points(&opponentMove.Card)
-			g.isOpponentMove = true
-		}
-		g.seenCards.AddCard(*g.cardPlayed)
-		g.seenCards.AddCard(opponentMove.Card)
-		g.cardPlayed = nil
+		g.completeTrick(opponentMove.Card)
 	}
 }
 
@@ -426,52 +696,84 @@ func (g *Game) UpdateOpponentMove(opponentMove Move) {
 // draws from the stack of cards after a move. If the drawn card is invalid
 // or it is not the time to draw cards a panic will occur.
 //
+// UpdateDrawnCard is a wrapper around TryUpdateDrawnCard that panics with
+// the message of the returned error.
+//
 // Note: the order of calls to GetMove, UpdateOpponentMove and
 // UpdateDrawnCard matters.
 func (g *Game) UpdateDrawnCard(card Card) {
+	if err := g.TryUpdateDrawnCard(card); err != nil {
+		panic(err.Error())
+	}
+}
+
+// TryUpdateDrawnCard updates the game state with the card that the AI
+// player draws from the stack of cards after a move. If the drawn card is
+// invalid or it is not the time to draw cards an error is returned and the
+// game state is left unchanged.
+//
+// Note: the order of calls to TryGetMove, TryUpdateOpponentMove and
+// TryUpdateDrawnCard matters.
+func (g *Game) TryUpdateDrawnCard(card Card) error {
+	if err := g.validateDrawnCard(card); err != nil {
+		return err
+	}
+
+	g.saveUndo()
+	g.applyDrawnCard(card)
+	return nil
+}
+
+func (g *Game) validateDrawnCard(card Card) error {
+	if g.IsOver() {
+		return ErrGameOver
+	}
+
 	if g.cardPlayed != nil {
-		panic("cannot draw cards in the middle of a play")
+		return ErrDrawMidTrick
 	}
 
 	if g.isClosed {
-		panic("should not draw cards when the game is closed")
+		return ErrDrawClosed
 	}
 
-	if len(g.hand) == 6 {
-		if len(g.seenCards) == 0 {
-			panic("should not draw cards before the first play")
-		} else {
-			panic("should not draw cards twice before playing")
+	if g.hand.Len() == 6 {
+		if g.seenCards.Len() == 0 {
+			return ErrDrawBeforeFirstPlay
 		}
+		return ErrDrawTwice
 	}
 
 	if g.seenCards.HasCard(card) {
-		panic("drawn card has been played before")
+		return ErrDrawnCardPlayed
 	}
 
 	if g.knownOpponentCards.HasCard(card) {
-		panic("cannot draw card that is in opponent's hand")
+		return ErrDrawnCardInOpponentHand
 	}
 
 	if g.hand.HasCard(card) {
-		panic("cannot draw card that is in the hand already")
+		return ErrDrawnCardInHand
 	}
 
 	if g.trumpCard == nil {
-		panic("all cards are drawn already")
+		return ErrAllCardsDrawn
 	}
 
-	if *g.trumpCard == card && len(g.seenCards) < 10 {
-		panic("cannot draw trump card yet")
+	if *g.trumpCard == card && g.seenCards.Len() < 10 {
+		return ErrDrawTrumpCardEarly
 	}
 
+	return nil
+}
+
+func (g *Game) applyDrawnCard(card Card) {
+	g.record(Event{Kind: EventDraw, Player: AI, Card: card})
 	g.hand.AddCard(card)
 	g.unseenCards.RemoveCard(card)
 
-	if len(g.seenCards) == 12 {
-		for card := range g.unseenCards {
-			g.knownOpponentCards.AddCard(card)
-		}
+	if g.seenCards.Len() == 12 {
+		g.knownOpponentCards = Hand{g.knownOpponentCards.cards.Union(g.unseenCards.cards)}
 		if card != *g.trumpCard {
 			g.knownOpponentCards.AddCard(*g.trumpCard)
 		}
@@ -1,6 +1,7 @@
 package santase
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -604,7 +605,7 @@ func TestHelperFunctions(t *testing.T) {
 
 		hidden := getHiddenCards(hand, trumpCard)
 
-		assert.Equal(t, 17, len(hidden))
+		assert.Equal(t, 17, hidden.Len())
 		assert.True(t, hidden.HasCard(NewCard(Jack, Spades)))
 		assert.True(t, hidden.HasCard(NewCard(Queen, Clubs)))
 		assert.True(t, hidden.HasCard(NewCard(King, Diamonds)))
@@ -624,3 +625,288 @@ func TestHelperFunctions(t *testing.T) {
 		assert.True(t, hidden.HasCard(NewCard(King, Hearts)))
 	})
 }
+
+type fixedAgent struct {
+	move Move
+}
+
+func (a fixedAgent) GetMove(g *Game) Move {
+	return a.move
+}
+
+func TestTryUpdateOpponentMove(t *testing.T) {
+	t.Run("returns typed error", func(t *testing.T) {
+		game := createSampleGame()
+		game.isOpponentMove = false
+
+		err := game.TryUpdateOpponentMove(Move{Card: NewCard(Ace, Diamonds)})
+		assert.Equal(t, ErrNotOpponentTurn, err)
+	})
+
+	t.Run("leaves game untouched on failure", func(t *testing.T) {
+		game := createSampleGame()
+
+		// simulating playing one hand
+		game.seenCards.AddCard(NewCard(King, Spades))
+		game.unseenCards.RemoveCard(NewCard(King, Spades))
+		game.seenCards.AddCard(NewCard(Ten, Spades))
+		game.unseenCards.RemoveCard(NewCard(Ten, Spades))
+		game.hand.RemoveCard(NewCard(King, Spades))
+		game.opponentScore = 14
+		game.hand.AddCard(NewCard(Jack, Hearts))
+		game.unseenCards.RemoveCard(NewCard(Jack, Hearts))
+
+		// switching is valid, but the announcement is not
+		err := game.TryUpdateOpponentMove(Move{
+			Card:            NewCard(King, Diamonds),
+			SwitchTrumpCard: true,
+			IsAnnouncement:  true,
+		})
+		assert.Equal(t, ErrMarriagePartnerInAIHand, err)
+		assert.Equal(t, NewCard(Ten, Clubs), *game.trumpCard)
+		assert.Equal(t, 0, game.knownOpponentCards.Len())
+		assert.True(t, game.unseenCards.HasCard(NewCard(Nine, Clubs)))
+		assert.Equal(t, 14, game.opponentScore)
+		assert.True(t, game.isOpponentMove)
+	})
+}
+
+func TestTryGetMove(t *testing.T) {
+	t.Run("returns typed error", func(t *testing.T) {
+		game := createSampleGame()
+
+		_, err := game.TryGetMove()
+		assert.Equal(t, ErrNotAITurn, err)
+	})
+
+	t.Run("illegal response", func(t *testing.T) {
+		game := createSampleGame()
+		game.isClosed = true
+		game.SetAgent(fixedAgent{Move{Card: NewCard(Ten, Hearts)}})
+		game.UpdateOpponentMove(Move{Card: NewCard(Jack, Spades)})
+
+		_, err := game.TryGetMove()
+		if assert.IsType(t, &IllegalResponseError{}, err) {
+			responseErr := err.(*IllegalResponseError)
+			assert.Equal(t, NewCard(Ten, Hearts), responseErr.Card)
+			assert.Equal(t, NewCard(Jack, Spades), responseErr.Played)
+			assert.Equal(t, "{ K♠ A♠ }", responseErr.Legal.String())
+		}
+		assert.True(t, game.hand.HasCard(NewCard(Ten, Hearts)))
+		assert.Equal(t, NewCard(Jack, Spades), *game.cardPlayed)
+	})
+
+	t.Run("switching and announcing with the trump card", func(t *testing.T) {
+		game := createSampleGameWithTrumpCard(NewCard(King, Diamonds))
+		game.isOpponentMove = false
+
+		// simulating playing one hand
+		game.seenCards.AddCard(NewCard(King, Spades))
+		game.unseenCards.RemoveCard(NewCard(King, Spades))
+		game.seenCards.AddCard(NewCard(Ten, Spades))
+		game.unseenCards.RemoveCard(NewCard(Ten, Spades))
+		game.hand.RemoveCard(NewCard(King, Spades))
+		game.score = 14
+		game.tricks = 1
+		game.hand.AddCard(NewCard(Jack, Hearts))
+		game.unseenCards.RemoveCard(NewCard(Jack, Hearts))
+
+		move := Move{
+			Card:            NewCard(Queen, Diamonds),
+			SwitchTrumpCard: true,
+			IsAnnouncement:  true,
+		}
+		game.SetAgent(fixedAgent{move})
+
+		result, err := game.TryGetMove()
+		assert.Nil(t, err)
+		assert.Equal(t, move, result)
+		assert.Equal(t, 54, game.score)
+		assert.True(t, game.hand.HasCard(NewCard(King, Diamonds)))
+		assert.Equal(t, NewCard(Nine, Diamonds), *game.trumpCard)
+	})
+}
+
+func TestTryUpdateDrawnCard(t *testing.T) {
+	game := createSampleGame()
+
+	err := game.TryUpdateDrawnCard(NewCard(Jack, Hearts))
+	assert.Equal(t, ErrDrawBeforeFirstPlay, err)
+	assert.False(t, game.hand.HasCard(NewCard(Jack, Hearts)))
+}
+
+func TestIsOver(t *testing.T) {
+	t.Run("new game", func(t *testing.T) {
+		game := createSampleGame()
+
+		assert.False(t, game.IsOver())
+		assert.Equal(t, Nobody, game.Winner())
+		assert.Equal(t, 0, game.GamePoints())
+	})
+
+	t.Run("after declaring", func(t *testing.T) {
+		game := createSampleGame()
+		game.isOpponentMove = false
+
+		// simulate the ai has collected enough points
+		game.score = 60
+		game.tricks = 4
+		game.opponentScore = 30
+		game.opponentTricks = 1
+		game.SetAgent(fixedAgent{Move{Card: NewCard(Ace, Spades)}})
+		game.GetMove()
+		game.UpdateOpponentMove(Move{Card: NewCard(Jack, Spades)})
+		assert.False(t, game.IsOver())
+
+		game.UpdateDrawnCard(NewCard(Jack, Hearts))
+		game.SetAgent(fixedAgent{Move{Declare: true}})
+		game.GetMove()
+
+		assert.True(t, game.IsOver())
+		assert.Equal(t, AI, game.Winner())
+		assert.Equal(t, 2, game.GamePoints())
+		assert.Equal(t, ErrGameOver, game.TryUpdateOpponentMove(Move{Card: NewCard(Jack, Clubs)}))
+	})
+
+	t.Run("after wrong declaration with announcement", func(t *testing.T) {
+		game := createSampleGame()
+
+		// simulating playing one hand
+		game.seenCards.AddCard(NewCard(King, Spades))
+		game.unseenCards.RemoveCard(NewCard(King, Spades))
+		game.seenCards.AddCard(NewCard(Ten, Spades))
+		game.unseenCards.RemoveCard(NewCard(Ten, Spades))
+		game.hand.RemoveCard(NewCard(King, Spades))
+		game.opponentScore = 14
+		game.opponentTricks = 1
+		game.hand.AddCard(NewCard(Jack, Hearts))
+		game.unseenCards.RemoveCard(NewCard(Jack, Hearts))
+
+		game.UpdateOpponentMove(Move{
+			Card:           NewCard(Queen, Hearts),
+			IsAnnouncement: true,
+			Declare:        true,
+		})
+
+		assert.Equal(t, 34, game.opponentScore)
+		assert.True(t, game.knownOpponentCards.HasCard(NewCard(Queen, Hearts)))
+		assert.True(t, game.knownOpponentCards.HasCard(NewCard(King, Hearts)))
+		assert.True(t, game.IsOver())
+		assert.Equal(t, AI, game.Winner())
+		assert.Equal(t, 3, game.GamePoints())
+	})
+
+	t.Run("with announcement when all opponent's cards are known", func(t *testing.T) {
+		game := createSampleGame()
+
+		// simulating playing one hand
+		game.seenCards.AddCard(NewCard(King, Spades))
+		game.unseenCards.RemoveCard(NewCard(King, Spades))
+		game.seenCards.AddCard(NewCard(Ten, Spades))
+		game.unseenCards.RemoveCard(NewCard(Ten, Spades))
+		game.hand.RemoveCard(NewCard(King, Spades))
+		game.opponentScore = 50
+		game.opponentTricks = 1
+		game.hand.AddCard(NewCard(Jack, Hearts))
+		game.unseenCards.RemoveCard(NewCard(Jack, Hearts))
+
+		// simulating knowing all of the opponent's cards
+		known := NewHand(
+			NewCard(Queen, Hearts),
+			NewCard(King, Hearts),
+			NewCard(Nine, Hearts),
+			NewCard(Ace, Hearts),
+			NewCard(Jack, Clubs),
+			NewCard(Queen, Clubs),
+		)
+		for _, card := range known.ToSlice() {
+			game.knownOpponentCards.AddCard(card)
+			game.unseenCards.RemoveCard(card)
+		}
+
+		game.UpdateOpponentMove(Move{
+			Card:           NewCard(Queen, Hearts),
+			IsAnnouncement: true,
+			Declare:        true,
+		})
+
+		assert.Equal(t, 70, game.opponentScore)
+		assert.Equal(t, known, game.knownOpponentCards)
+		assert.True(t, game.IsOver())
+		assert.Equal(t, Opponent, game.Winner())
+	})
+}
+
+func TestDeclarationInvalidSituations(t *testing.T) {
+	t.Run("declaring when second to play", func(t *testing.T) {
+		game := createSampleGame()
+
+		// simulate if ai has played first move
+		card := NewCard(Ace, Spades)
+		game.cardPlayed = &card
+		game.hand.RemoveCard(card)
+
+		assert.Equal(t, ErrDeclareNotFirst, game.TryUpdateOpponentMove(Move{Declare: true}))
+	})
+
+	t.Run("declaring and closing", func(t *testing.T) {
+		game := createSampleGame()
+
+		assert.Equal(t, ErrInvalidDeclaration, game.TryUpdateOpponentMove(Move{Declare: true, CloseGame: true}))
+	})
+
+	t.Run("declaring with announcement of card in ai's hand", func(t *testing.T) {
+		game := createSampleGame()
+
+		err := game.TryUpdateOpponentMove(Move{
+			Card:           NewCard(Queen, Diamonds),
+			IsAnnouncement: true,
+			Declare:        true,
+		})
+		assert.Equal(t, ErrCardInAIHand, err)
+	})
+}
+
+// contextAgent plays the lowest card and remembers if the
+// context it was given was done.
+type contextAgent struct {
+	cancelled *bool
+}
+
+func (a contextAgent) GetMove(g *Game) Move {
+	return a.GetMoveContext(context.Background(), g)
+}
+
+func (a contextAgent) GetMoveContext(ctx context.Context, g *Game) Move {
+	*a.cancelled = ctx.Err() != nil
+	return lowestCardAgent{}.GetMove(g)
+}
+
+func TestGetMoveContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Run("context agent", func(t *testing.T) {
+		var cancelled bool
+		game := createSampleGame()
+		game.isOpponentMove = false
+		game.SetAgent(contextAgent{&cancelled})
+
+		move, err := game.TryGetMoveContext(ctx)
+		assert.Nil(t, err)
+		assert.True(t, cancelled)
+		assert.Equal(t, NewCard(Nine, Diamonds), move.Card)
+
+		game.Undo()
+		game.GetMove()
+		assert.False(t, cancelled)
+	})
+
+	t.Run("plain agent", func(t *testing.T) {
+		game := createSampleGame()
+		game.isOpponentMove = false
+		game.SetAgent(fixedAgent{Move{Card: NewCard(Ace, Spades)}})
+
+		assert.Equal(t, Move{Card: NewCard(Ace, Spades)}, game.GetMoveContext(ctx))
+	})
+}
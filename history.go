@@ -0,0 +1,121 @@
+package santase
+
+import "fmt"
+
+// EventKind tells what happened in an Event.
+type EventKind int
+
+// EventMove is recorded for every move a player makes. It is followed
+// by the events that make up the move: EventSwitch when the trump card
+// is switched, EventClose when the game is closed, EventAnnouncement
+// when a marriage is announced and EventDeclaration when the player
+// declares. EventTrick is recorded when a trick is completed and
+// EventDraw when the AI draws a card from the stack.
+const (
+	EventMove EventKind = iota
+	EventSwitch
+	EventClose
+	EventAnnouncement
+	EventDeclaration
+	EventTrick
+	EventDraw
+)
+
+var eventKindStrings = map[EventKind]string{
+	EventMove:         "move",
+	EventSwitch:       "switch",
+	EventClose:        "close",
+	EventAnnouncement: "announcement",
+	EventDeclaration:  "declaration",
+	EventTrick:        "trick",
+	EventDraw:         "draw",
+}
+
+func (k EventKind) String() string {
+	if str, ok := eventKindStrings[k]; ok {
+		return str
+	}
+	return "invalid"
+}
+
+// MarshalText encodes the kind as its name (e.g. "trick").
+func (k EventKind) MarshalText() ([]byte, error) {
+	str, ok := eventKindStrings[k]
+	if !ok {
+		return nil, fmt.Errorf("invalid event kind %d", int(k))
+	}
+	return []byte(str), nil
+}
+
+// UnmarshalText decodes a kind encoded as its name.
+func (k *EventKind) UnmarshalText(text []byte) error {
+	for kind, str := range eventKindStrings {
+		if str == string(text) {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid event kind %q", text)
+}
+
+// Event is an entry in the history of a Game (see Game.History).
+//
+// Player is the player that made the move, switched the trump card,
+// closed the game, announced, declared, won the trick or drew a card.
+// The rest of the fields are set depending on the kind of the event:
+//
+//	EventMove:         Move is the move played
+//	EventSwitch:       Card is the trump card taken with the nine of trump
+//	EventAnnouncement: Card is the announced card and Points the points
+//	                   of the marriage (pending until the player wins a
+//	                   trick, see Rules.DeferAnnouncements)
+//	EventTrick:        Leader played Lead, the other player played
+//	                   Response and Points are the points Player won
+//	EventDraw:         Card is the card drawn
+type Event struct {
+	Kind     EventKind `json:"kind"`
+	Player   Player    `json:"player"`
+	Move     Move      `json:"move"`
+	Card     Card      `json:"card"`
+	Leader   Player    `json:"leader,omitempty"`
+	Lead     Card      `json:"lead"`
+	Response Card      `json:"response"`
+	Points   int       `json:"points,omitempty"`
+}
+
+func (e Event) String() string {
+	switch e.Kind {
+	case EventMove:
+		if e.Move.Declare {
+			return fmt.Sprintf("%s declares instead of playing", e.Player)
+		}
+		return fmt.Sprintf("%s plays %s", e.Player, e.Move.Card)
+	case EventSwitch:
+		return fmt.Sprintf("%s switches the trump card %s", e.Player, e.Card)
+	case EventClose:
+		return fmt.Sprintf("%s closes the game", e.Player)
+	case EventAnnouncement:
+		return fmt.Sprintf("%s announces %s for %d", e.Player, e.Card, e.Points)
+	case EventDeclaration:
+		return fmt.Sprintf("%s declares", e.Player)
+	case EventTrick:
+		return fmt.Sprintf("%s wins %s %s for %d", e.Player, e.Lead, e.Response, e.Points)
+	case EventDraw:
+		return fmt.Sprintf("%s draws %s", e.Player, e.Card)
+	}
+	return "invalid"
+}
+
+// History returns the events of the game in the order they happened.
+//
+// Cards drawn by the opponent are not known to the AI and are not
+// part of the history.
+func (g *Game) History() []Event {
+	history := make([]Event, len(g.history))
+	copy(history, g.history)
+	return history
+}
+
+func (g *Game) record(event Event) {
+	g.history = append(g.history, event)
+}
@@ -0,0 +1,82 @@
+package santase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	game := createSampleGame()
+	assert.Empty(t, game.History())
+
+	game.UpdateOpponentMove(Move{Card: NewCard(Jack, Spades)})
+	game.SetAgent(fixedAgent{Move{Card: NewCard(Ace, Spades)}})
+	game.GetMove()
+	game.UpdateDrawnCard(NewCard(Jack, Hearts))
+	game.SetAgent(fixedAgent{Move{Card: NewCard(Ten, Hearts), CloseGame: true}})
+	game.GetMove()
+	game.UpdateOpponentMove(Move{Card: NewCard(Ace, Hearts)})
+	game.UpdateOpponentMove(Move{Card: NewCard(Queen, Hearts), IsAnnouncement: true})
+
+	expected := []Event{
+		{Kind: EventMove, Player: Opponent, Move: Move{Card: NewCard(Jack, Spades)}},
+		{Kind: EventMove, Player: AI, Move: Move{Card: NewCard(Ace, Spades)}},
+		{
+			Kind:     EventTrick,
+			Player:   AI,
+			Leader:   Opponent,
+			Lead:     NewCard(Jack, Spades),
+			Response: NewCard(Ace, Spades),
+			Points:   13,
+		},
+		{Kind: EventDraw, Player: AI, Card: NewCard(Jack, Hearts)},
+		{Kind: EventMove, Player: AI, Move: Move{Card: NewCard(Ten, Hearts), CloseGame: true}},
+		{Kind: EventClose, Player: AI},
+		{Kind: EventMove, Player: Opponent, Move: Move{Card: NewCard(Ace, Hearts)}},
+		{
+			Kind:     EventTrick,
+			Player:   Opponent,
+			Leader:   AI,
+			Lead:     NewCard(Ten, Hearts),
+			Response: NewCard(Ace, Hearts),
+			Points:   21,
+		},
+		{Kind: EventMove, Player: Opponent, Move: Move{Card: NewCard(Queen, Hearts), IsAnnouncement: true}},
+		{Kind: EventAnnouncement, Player: Opponent, Card: NewCard(Queen, Hearts), Points: 20},
+	}
+	assert.Equal(t, expected, game.History())
+
+	// the returned history is a copy
+	history := game.History()
+	history[0].Player = AI
+	assert.Equal(t, Opponent, game.History()[0].Player)
+}
+
+func TestHistorySwitchAndDeclaration(t *testing.T) {
+	game := createSampleGameWithTrumpCard(NewCard(King, Diamonds))
+	game.isOpponentMove = false
+
+	// simulating playing one hand
+	game.seenCards.AddCard(NewCard(King, Spades))
+	game.unseenCards.RemoveCard(NewCard(King, Spades))
+	game.seenCards.AddCard(NewCard(Ten, Spades))
+	game.unseenCards.RemoveCard(NewCard(Ten, Spades))
+	game.hand.RemoveCard(NewCard(King, Spades))
+	game.score = 50
+	game.tricks = 1
+	game.hand.AddCard(NewCard(Jack, Hearts))
+	game.unseenCards.RemoveCard(NewCard(Jack, Hearts))
+
+	game.SetAgent(fixedAgent{Move{Card: NewCard(Jack, Hearts), SwitchTrumpCard: true}})
+	game.GetMove()
+	game.UpdateOpponentMove(Move{Card: NewCard(Ace, Hearts)})
+	game.UpdateOpponentMove(Move{Declare: true})
+
+	history := game.History()
+	assert.Equal(t, Event{Kind: EventSwitch, Player: AI, Card: NewCard(King, Diamonds)}, history[1])
+	assert.Equal(t, EventTrick, history[3].Kind)
+	assert.Equal(t, Event{Kind: EventDeclaration, Player: Opponent}, history[len(history)-1])
+	assert.Equal(t, "opponent declares", history[len(history)-1].String())
+	assert.Equal(t, "opponent wins J♥ A♥ for 13", history[3].String())
+}
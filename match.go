@@ -0,0 +1,151 @@
+package santase
+
+// matchTarget is the number of game points needed to win a match.
+const matchTarget = 11
+
+// DealerRule decides who deals the next deal of a Match.
+// The player that does not deal leads the first trick.
+type DealerRule int
+
+// DealerRotation makes the players take turns dealing and LoserDeals
+// makes the loser of the previous deal deal the next one.
+const (
+	DealerRotation DealerRule = iota
+	LoserDeals
+)
+
+// Match is a sequence of deals played until one of the players
+// collects 11 game points. Like Game it is from the point of view
+// of the AI.
+//
+// Each deal is a Game created by NewDeal, which can be played either
+// by calling the Game methods directly or at a Table (see PlayMatch).
+type Match struct {
+	dealerRule      DealerRule
+	isOpponentFirst bool
+	deals           []*Game
+	rules           Rules
+	agent           Agent
+}
+
+// NewMatch creates a new Match given the rule used to choose the
+// dealer and whether the opponent leads the first deal.
+func NewMatch(dealerRule DealerRule, isOpponentFirst bool) *Match {
+	return &Match{
+		dealerRule:      dealerRule,
+		isOpponentFirst: isOpponentFirst,
+		rules:           StandardRules,
+		agent:           dummyAgent{},
+	}
+}
+
+// SetAgent sets the Agent used by the AI in every following deal
+// of the match (see Game.SetAgent).
+func (m *Match) SetAgent(agent Agent) {
+	m.agent = agent
+}
+
+// SetRules sets the rules every following deal of the match is
+// played with. By default deals are played with the StandardRules.
+func (m *Match) SetRules(rules Rules) {
+	m.rules = rules
+}
+
+// NewDeal starts the next deal of the match given the hand of the AI
+// and the trump card. Who plays first is decided by the dealer rule of
+// the match (see IsOpponentFirst).
+//
+// An error is returned if the current deal is not over yet or if the
+// match is over. If the hand does not have 6 cards a panic will occur.
+func (m *Match) NewDeal(hand Hand, trumpCard Card) (*Game, error) {
+	if m.IsOver() {
+		return nil, ErrMatchOver
+	}
+
+	if current := m.Game(); current != nil && !current.IsOver() {
+		return nil, ErrDealInProgress
+	}
+
+	game := CreateGameWithRules(hand, trumpCard, m.IsOpponentFirst(), m.rules)
+	game.SetAgent(m.agent)
+	m.deals = append(m.deals, &game)
+	return &game, nil
+}
+
+// Game returns the current (or last) deal of the match.
+// If no deal has been started the result will be nil.
+func (m *Match) Game() *Game {
+	if len(m.deals) == 0 {
+		return nil
+	}
+	return m.deals[len(m.deals)-1]
+}
+
+// Deals returns all the deals of the match in the order they were played.
+func (m *Match) Deals() []*Game {
+	deals := make([]*Game, len(m.deals))
+	copy(deals, m.deals)
+	return deals
+}
+
+// IsOpponentFirst returns if the opponent leads the next deal. While a
+// deal is in progress it returns who led it.
+func (m *Match) IsOpponentFirst() bool {
+	last := m.Game()
+	if last == nil || !last.IsOver() {
+		return m.leader(len(m.deals) - 1)
+	}
+	return m.leader(len(m.deals))
+}
+
+// leader returns if the opponent leads the i-th deal.
+func (m *Match) leader(i int) bool {
+	isOpponentFirst := m.isOpponentFirst
+	for j := 0; j < i; j++ {
+		switch m.dealerRule {
+		case LoserDeals:
+			isOpponentFirst = m.deals[j].Winner() == Opponent
+		default:
+			isOpponentFirst = !isOpponentFirst
+		}
+	}
+	return isOpponentFirst
+}
+
+// GetScore returns the game points the AI has collected in finished deals.
+func (m *Match) GetScore() int {
+	return m.points(AI)
+}
+
+// GetOpponentScore returns the game points the opponent has collected
+// in finished deals.
+func (m *Match) GetOpponentScore() int {
+	return m.points(Opponent)
+}
+
+func (m *Match) points(player Player) int {
+	points := 0
+	for _, deal := range m.deals {
+		if deal.Winner() == player {
+			points += deal.GamePoints()
+		}
+	}
+	return points
+}
+
+// IsOver returns if one of the players has collected 11 game points.
+func (m *Match) IsOver() bool {
+	return m.Winner() != Nobody
+}
+
+// Winner returns the player that won the match or Nobody if the
+// match is not over yet.
+func (m *Match) Winner() Player {
+	if m.GetScore() >= matchTarget {
+		return AI
+	}
+	if m.GetOpponentScore() >= matchTarget {
+		return Opponent
+	}
+	return Nobody
+}
@@ -0,0 +1,84 @@
+package santase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// finishDeal makes the given player win the deal with 3 game points.
+func finishDeal(game *Game, winner Player) {
+	if winner == AI {
+		game.score = 66
+	} else {
+		game.opponentScore = 66
+	}
+	game.declaredBy = winner
+}
+
+func TestMatchNewDeal(t *testing.T) {
+	match := NewMatch(DealerRotation, false)
+	assert.Nil(t, match.Game())
+
+	game, err := match.NewDeal(createSampleHand(), NewCard(Ten, Clubs))
+	assert.Nil(t, err)
+	assert.Equal(t, game, match.Game())
+	assert.False(t, game.IsOpponentMove())
+
+	_, err = match.NewDeal(createSampleHand(), NewCard(Ten, Clubs))
+	assert.Equal(t, ErrDealInProgress, err)
+}
+
+func TestMatchScore(t *testing.T) {
+	match := NewMatch(DealerRotation, true)
+
+	for _, winner := range []Player{AI, Opponent, AI, AI} {
+		game, err := match.NewDeal(createSampleHand(), NewCard(Ten, Clubs))
+		assert.Nil(t, err)
+		assert.False(t, match.IsOver())
+		finishDeal(game, winner)
+	}
+
+	assert.Equal(t, 9, match.GetScore())
+	assert.Equal(t, 3, match.GetOpponentScore())
+	assert.Equal(t, 4, len(match.Deals()))
+
+	game, _ := match.NewDeal(createSampleHand(), NewCard(Ten, Clubs))
+	finishDeal(game, AI)
+
+	assert.True(t, match.IsOver())
+	assert.Equal(t, AI, match.Winner())
+
+	_, err := match.NewDeal(createSampleHand(), NewCard(Ten, Clubs))
+	assert.Equal(t, ErrMatchOver, err)
+}
+
+func TestMatchDealerRule(t *testing.T) {
+	t.Run("dealer rotation", func(t *testing.T) {
+		match := NewMatch(DealerRotation, true)
+
+		for _, isOpponentFirst := range []bool{true, false, true, false} {
+			assert.Equal(t, isOpponentFirst, match.IsOpponentFirst())
+			game, _ := match.NewDeal(createSampleHand(), NewCard(Ten, Clubs))
+			assert.Equal(t, isOpponentFirst, game.IsOpponentMove())
+			assert.Equal(t, isOpponentFirst, match.IsOpponentFirst())
+			finishDeal(game, Opponent)
+		}
+	})
+
+	t.Run("loser deals", func(t *testing.T) {
+		match := NewMatch(LoserDeals, false)
+
+		game, _ := match.NewDeal(createSampleHand(), NewCard(Ten, Clubs))
+		finishDeal(game, Opponent)
+		assert.True(t, match.IsOpponentFirst())
+
+		game, _ = match.NewDeal(createSampleHand(), NewCard(Ten, Clubs))
+		finishDeal(game, Opponent)
+		assert.True(t, match.IsOpponentFirst())
+
+		game, _ = match.NewDeal(createSampleHand(), NewCard(Ten, Clubs))
+		finishDeal(game, AI)
+		assert.False(t, match.IsOpponentFirst())
+	})
+}
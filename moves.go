@@ -0,0 +1,112 @@
+package santase
+
+// LegalMoves returns every move the side to act can play, with all
+// the combinations of switching the trump card, closing the game and
+// announcing that are allowed. Declarations are included as well,
+// whether or not they would win. The moves are checked exactly like
+// the moves passed to GetMove and UpdateOpponentMove.
+//
+// When it is the opponent's turn the moves are the ones that are legal
+// with any of the cards the opponent may hold, because their hand is
+// not known. Once all of their cards are known (e.g. after the trump
+// card is taken), they have to follow suit like the AI. No moves are
+// returned if the game is over or a drawn card is pending.
+//
+// The moves are sorted by card (see AllCards), with the declarations
+// last.
+func (g *Game) LegalMoves() []Move {
+	if g.IsOver() || g.isDrawPending() {
+		return nil
+	}
+
+	if g.isOpponentMove {
+		return legalMoves(FullDeck, g.validateOpponentMove)
+	}
+	p := g.position(g.hand)
+	return legalMoves(p.cards(), p.validateMove)
+}
+
+// canAnnounceWith returns if a marriage can be announced with the card,
+// which has to be a queen or a king.
+func canAnnounceWith(card Card) bool {
+	return card.Rank == Queen || card.Rank == King
+}
+
+// legalMoves returns the moves with the given cards that pass validate,
+// in the order described in Game.LegalMoves.
+func legalMoves(cards CardSet, validate func(Move) error) []Move {
+	var moves []Move
+	flags := [2]bool{false, true}
+	for _, card := range cards.Cards() {
+		for _, switchTrumpCard := range flags {
+			for _, closeGame := range flags {
+				for _, isAnnouncement := range flags {
+					if isAnnouncement && !canAnnounceWith(card) {
+						continue
+					}
+
+					move := Move{
+						Card:            card,
+						IsAnnouncement:  isAnnouncement,
+						SwitchTrumpCard: switchTrumpCard,
+						CloseGame:       closeGame,
+					}
+					if validate(move) == nil {
+						moves = append(moves, move)
+					}
+				}
+			}
+		}
+	}
+
+	if validate(Move{Declare: true}) == nil {
+		moves = append(moves, Move{Declare: true})
+	}
+	for _, card := range cards.Cards() {
+		move := Move{Card: card, IsAnnouncement: true, Declare: true}
+		if canAnnounceWith(card) && validate(move) == nil {
+			moves = append(moves, move)
+		}
+	}
+
+	return moves
+}
+
+// ValidateMove checks if the AI can play the move, without changing
+// the game. It returns nil if the move is legal and a *MoveError
+// otherwise, which names the violated rule and lists the legal moves.
+// The move is checked exactly like the moves chosen by the agent in
+// GetMove.
+func (g *Game) ValidateMove(move Move) error {
+	return g.moveError(move, g.validateMove(move), false)
+}
+
+// ValidateOpponentMove checks if the opponent can play the move, without
+// changing the game. It returns nil if the move is legal and a *MoveError
+// otherwise, which names the violated rule and lists the legal moves.
+// The move is checked exactly like the moves passed to UpdateOpponentMove.
+func (g *Game) ValidateOpponentMove(move Move) error {
+	return g.moveError(move, g.validateOpponentMove(move), true)
+}
+
+// moveError wraps the error of validating a move of the AI or the
+// opponent in a *MoveError. The legal moves are listed only if it
+// is the turn of the player who made the move.
+func (g *Game) moveError(move Move, err error, isOpponentMove bool) error {
+	if err == nil {
+		return nil
+	}
+
+	var legal []Move
+	if g.isOpponentMove == isOpponentMove {
+		legal = g.LegalMoves()
+	}
+
+	return &MoveError{
+		Move:  move,
+		Err:   err,
+		Legal: legal,
+		rules: g.rules,
+		trump: g.trump,
+	}
+}
@@ -0,0 +1,267 @@
+package santase
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLegalMovesFirstMove(t *testing.T) {
+	game := createSampleGame()
+	game.isOpponentMove = false
+
+	expected := []Move{
+		{Card: NewCard(Nine, Diamonds)},
+		{Card: NewCard(Queen, Diamonds)},
+		{Card: NewCard(Ten, Hearts)},
+		{Card: NewCard(Nine, Spades)},
+		{Card: NewCard(King, Spades)},
+		{Card: NewCard(Ace, Spades)},
+		{Declare: true},
+	}
+	assert.Equal(t, expected, game.LegalMoves())
+}
+
+func TestLegalMovesSwitchCloseAndAnnounce(t *testing.T) {
+	game := createSampleGameWithTrumpCard(NewCard(King, Diamonds))
+	game.UpdateOpponentMove(Move{Card: NewCard(Jack, Spades)})
+	game.SetAgent(fixedAgent{Move{Card: NewCard(Ace, Spades)}})
+	game.GetMove()
+	game.UpdateDrawnCard(NewCard(Jack, Hearts))
+
+	moves := game.LegalMoves()
+	assert.Len(t, moves, 29)
+	for _, move := range moves {
+		assert.Nil(t, game.validateMove(move), "%+v", move)
+	}
+
+	assert.Contains(t, moves, Move{Card: NewCard(Jack, Hearts), CloseGame: true})
+	assert.Contains(t, moves, Move{Card: NewCard(King, Diamonds), SwitchTrumpCard: true})
+	assert.Contains(t, moves, Move{Card: NewCard(Queen, Diamonds), IsAnnouncement: true, SwitchTrumpCard: true, CloseGame: true})
+	assert.NotContains(t, moves, Move{Card: NewCard(Queen, Diamonds), IsAnnouncement: true})
+	assert.NotContains(t, moves, Move{Card: NewCard(Nine, Diamonds), SwitchTrumpCard: true})
+}
+
+func TestLegalMovesClosedResponse(t *testing.T) {
+	game := createSampleGame()
+	game.UpdateOpponentMove(Move{Card: NewCard(Jack, Spades)})
+	game.SetAgent(fixedAgent{Move{Card: NewCard(Ace, Spades)}})
+	game.GetMove()
+	game.UpdateDrawnCard(NewCard(Jack, Hearts))
+	game.SetAgent(fixedAgent{Move{Card: NewCard(Ten, Hearts), CloseGame: true}})
+	game.GetMove()
+	game.UpdateOpponentMove(Move{Card: NewCard(Ace, Hearts)})
+	game.UpdateOpponentMove(Move{Card: NewCard(Queen, Spades)})
+
+	// the king of spades is the only card that follows suit with a higher card
+	assert.Equal(t, []Move{{Card: NewCard(King, Spades)}}, game.LegalMoves())
+}
+
+func TestValidateMoveClosedResponse(t *testing.T) {
+	game := createSampleGame()
+	game.UpdateOpponentMove(Move{Card: NewCard(Jack, Spades)})
+	game.SetAgent(fixedAgent{Move{Card: NewCard(Ace, Spades)}})
+	game.GetMove()
+	game.UpdateDrawnCard(NewCard(Jack, Hearts))
+	game.SetAgent(fixedAgent{Move{Card: NewCard(Ten, Hearts), CloseGame: true}})
+	game.GetMove()
+	game.UpdateOpponentMove(Move{Card: NewCard(Ace, Hearts)})
+	game.UpdateOpponentMove(Move{Card: NewCard(Queen, Spades)})
+	snapshot := game.Snapshot()
+
+	assert.Nil(t, game.ValidateMove(Move{Card: NewCard(King, Spades)}))
+
+	err := game.ValidateMove(Move{Card: NewCard(Nine, Spades)})
+	var moveErr *MoveError
+	if assert.True(t, errors.As(err, &moveErr)) {
+		assert.Equal(t, Move{Card: NewCard(Nine, Spades)}, moveErr.Move)
+		assert.Equal(t, []Move{{Card: NewCard(King, Spades)}}, moveErr.Legal)
+	}
+	var responseErr *IllegalResponseError
+	assert.True(t, errors.As(err, &responseErr))
+	assert.EqualError(
+		t, err,
+		"illegal move 9S: invalid response card: 9♠ - must follow suit with a higher card "+
+			"because the game is closed (legal moves: KS)",
+	)
+
+	assert.Equal(t, snapshot, game.Snapshot())
+}
+
+func TestValidateMoveWrapsRule(t *testing.T) {
+	game := createSampleGame()
+	game.isOpponentMove = false
+
+	err := game.ValidateMove(Move{Card: NewCard(Nine, Diamonds), CloseGame: true})
+	assert.True(t, errors.Is(err, ErrCloseOnFirstMove))
+	assert.EqualError(
+		t, err,
+		"illegal move 9D+close: cannot close game on first move "+
+			"(legal moves: 9D, QD, 10H, 9S, KS, AS, declare)",
+	)
+}
+
+func TestValidateMoveNotAITurn(t *testing.T) {
+	game := createSampleGame()
+
+	err := game.ValidateMove(Move{Card: NewCard(Nine, Diamonds)})
+	assert.True(t, errors.Is(err, ErrNotAITurn))
+	assert.EqualError(t, err, "illegal move 9D: not AI's turn")
+}
+
+func TestValidateMoveDrawPending(t *testing.T) {
+	game := createSampleGame()
+	game.UpdateOpponentMove(Move{Card: NewCard(Jack, Spades)})
+	game.SetAgent(fixedAgent{Move{Card: NewCard(Ace, Spades)}})
+	game.GetMove()
+
+	// the AI has to draw its card before declaring, like before any other move
+	for _, move := range []Move{{Declare: true}, {Card: NewCard(King, Spades)}} {
+		err := game.ValidateMove(move)
+		assert.True(t, errors.Is(err, ErrDrawPending), "%v", err)
+	}
+	assert.Nil(t, game.LegalMoves())
+
+	game.SetAgent(fixedAgent{Move{Declare: true}})
+	_, err := game.TryGetMove()
+	assert.Equal(t, ErrDrawPending, err)
+}
+
+func TestValidateOpponentMove(t *testing.T) {
+	game := createSampleGame()
+	snapshot := game.Snapshot()
+
+	assert.Nil(t, game.ValidateOpponentMove(Move{Card: NewCard(Ace, Diamonds)}))
+
+	err := game.ValidateOpponentMove(Move{Card: NewCard(Nine, Diamonds)})
+	assert.True(t, errors.Is(err, ErrCardInAIHand))
+	var moveErr *MoveError
+	if assert.True(t, errors.As(err, &moveErr)) {
+		assert.Equal(t, game.LegalMoves(), moveErr.Legal)
+		assert.NotContains(t, moveErr.Legal, Move{Card: NewCard(Nine, Diamonds)})
+	}
+
+	assert.Equal(t, snapshot, game.Snapshot())
+}
+
+func TestValidateOpponentMoveFollowSuit(t *testing.T) {
+	game := createGameAllCardsDrawn()
+
+	assert.Nil(t, game.ValidateOpponentMove(Move{Card: NewCard(Ace, Spades)}))
+
+	err := game.ValidateOpponentMove(Move{Card: NewCard(Jack, Hearts)})
+	var responseErr *IllegalResponseError
+	if assert.True(t, errors.As(err, &responseErr), "%v", err) {
+		assert.Equal(t, NewCard(Nine, Spades), responseErr.Played)
+		assert.Equal(t, NewHand(NewCard(Ace, Spades)), responseErr.Legal)
+	}
+	var moveErr *MoveError
+	if assert.True(t, errors.As(err, &moveErr)) {
+		assert.Equal(t, []Move{{Card: NewCard(Ace, Spades)}}, moveErr.Legal)
+	}
+	assert.EqualError(
+		t, err,
+		"illegal move JH: invalid response card: J♥ - must follow suit with a higher card "+
+			"because all cards have been drawn (legal moves: AS)",
+	)
+}
+
+func TestLegalMovesOpponent(t *testing.T) {
+	game := createSampleGame()
+
+	moves := game.LegalMoves()
+	// any of the 17 cards the AI has not seen and a declaration
+	assert.Len(t, moves, 18)
+	assert.Equal(t, Move{Declare: true}, moves[len(moves)-1])
+	for _, move := range moves[:17] {
+		assert.True(t, game.unseenCards.HasCard(move.Card))
+	}
+
+	// the opponent, whose cards are all known, has to follow suit
+	game = createGameAllCardsDrawn()
+	assert.Equal(t, []Move{{Card: NewCard(Ace, Spades)}}, game.LegalMoves())
+}
+
+// createGameAllCardsDrawn returns a game in which all cards have been
+// drawn and the opponent, who is known to hold the ace of spades, has
+// to respond to the nine of spades.
+func createGameAllCardsDrawn() Game {
+	game := createSampleGame()
+	game.hand = NewHand(
+		NewCard(Nine, Diamonds),
+		NewCard(Queen, Diamonds),
+		NewCard(King, Diamonds),
+		NewCard(Ten, Hearts),
+		NewCard(Ace, Hearts),
+	)
+	game.knownOpponentCards = NewHand(
+		NewCard(Ace, Spades),
+		NewCard(Jack, Hearts),
+		NewCard(Queen, Hearts),
+		NewCard(King, Hearts),
+		NewCard(Nine, Clubs),
+		NewCard(Jack, Clubs),
+	)
+	game.seenCards = NewPile()
+	for _, card := range []Card{
+		NewCard(Jack, Spades), NewCard(Queen, Spades), NewCard(King, Spades), NewCard(Ten, Spades),
+		NewCard(Jack, Diamonds), NewCard(Ten, Diamonds), NewCard(Ace, Diamonds), NewCard(Nine, Hearts),
+		NewCard(Queen, Clubs), NewCard(King, Clubs), NewCard(Ten, Clubs), NewCard(Ace, Clubs),
+	} {
+		game.seenCards.AddCard(card)
+	}
+	game.unseenCards = NewPile()
+	game.trumpCard = nil
+
+	card := NewCard(Nine, Spades)
+	game.cardPlayed = &card
+	game.isOpponentMove = true
+	return game
+}
+
+func TestLegalMovesNone(t *testing.T) {
+	game := createSampleGame()
+	game.UpdateOpponentMove(Move{Card: NewCard(Jack, Spades)})
+	game.SetAgent(fixedAgent{Move{Card: NewCard(Ace, Spades)}})
+	game.GetMove()
+	assert.Nil(t, game.LegalMoves())
+
+	game.UpdateDrawnCard(NewCard(Jack, Hearts))
+	game.SetAgent(fixedAgent{Move{Declare: true}})
+	game.GetMove()
+	assert.True(t, game.IsOver())
+	assert.Nil(t, game.LegalMoves())
+}
+
+// legalMovesAgent plays a random move out of the legal moves
+// that do not declare.
+type legalMovesAgent struct {
+	rng *rand.Rand
+}
+
+func (a legalMovesAgent) GetMove(g *Game) Move {
+	var moves []Move
+	for _, move := range g.LegalMoves() {
+		if !move.Declare {
+			moves = append(moves, move)
+		}
+	}
+	return moves[a.rng.Intn(len(moves))]
+}
+
+func TestLegalMovesAreAccepted(t *testing.T) {
+	rules := StandardRules
+	rules.AnnounceOnFirstTrick = true
+	rules.SwitchWhenResponding = true
+
+	for _, rules := range []Rules{StandardRules, rules} {
+		table := NewTable(legalMovesAgent{rand.New(rand.NewSource(1))}, legalMovesAgent{rand.New(rand.NewSource(2))})
+		table.SetRules(rules)
+		for i := 0; i < 50; i++ {
+			_, err := table.Play()
+			assert.Nil(t, err)
+		}
+	}
+}
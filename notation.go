@@ -0,0 +1,614 @@
+package santase
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Record is a complete (or partially played) deal written down in
+// the santase notation read by Parser and written by Writer.
+//
+// The notation is similar to PGN. A record starts with headers, one
+// per line, followed by the moves, one trick per line:
+//
+//	[Trump "10C"]
+//	[First "opponent"]
+//	[Hand "9D QD 10H 9S KS AS"]
+//	[TargetScore "66"]
+//	[Result "ai 2"]
+//
+//	1. JS AS (JH)
+//	2. 10H+close AH
+//	3. QH+40+switch 9H
+//
+// Trump is the trump card, First is the player that leads the first
+// trick, Hand and OpponentHand are the cards dealt to the AI and to
+// the opponent and Result is the winner and the game points they
+// earned (or "*" if the deal is not over). The rules of the deal are
+// given with headers named after the fields of Rules (StandardRules
+// are used for missing ones). Trump, First and Hand are required,
+// unknown headers are ignored.
+//
+// Each trick line starts with the number of the trick followed by the
+// move of the leader and the move of the responding player. Cards use
+// the notation of Card.MarshalText. A move can be annotated with +20
+// or +40 (the points of an announcement), +switch and +close. A
+// declaration is written as "declare" or, when combined with an
+// announcement, as the announced card followed by the points and
+// +declare (e.g. "QH+40+declare"). The card the AI draws after a
+// trick is written in parentheses. Lines starting with ";" are
+// comments.
+//
+// Like Game a record is from the point of view of the AI. Only the
+// moves and the draws of the events are written, the rest of the
+// events follow from them.
+type Record struct {
+	Rules           Rules
+	TrumpCard       Card
+	IsOpponentFirst bool
+	Hand            Hand
+	OpponentHand    *Hand
+	Events          []Event
+	Winner          Player
+	GamePoints      int
+}
+
+// NewRecord creates a record of the game played so far. The hand of
+// the opponent is not known to the AI, so OpponentHand is nil.
+func NewRecord(game *Game) Record {
+	history := game.History()
+
+	isOpponentFirst := game.IsOpponentMove()
+	for _, event := range history {
+		if event.Kind == EventMove {
+			isOpponentFirst = event.Player == Opponent
+			break
+		}
+	}
+
+	return Record{
+		Rules:           game.GetRules(),
+		TrumpCard:       game.initialTrumpCard,
+		IsOpponentFirst: isOpponentFirst,
+		Hand:            game.initialHand.Clone(),
+		Events:          history,
+		Winner:          game.Winner(),
+		GamePoints:      game.GamePoints(),
+	}
+}
+
+// Writer writes records in the santase notation (see Record).
+type Writer struct {
+	w       io.Writer
+	written bool
+}
+
+// NewWriter creates a Writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// WriteGame writes a record of the game played so far.
+func (w *Writer) WriteGame(game *Game) error {
+	return w.WriteRecord(NewRecord(game))
+}
+
+// WriteRecord writes the record. Records written one after
+// the other are separated by an empty line.
+func (w *Writer) WriteRecord(r Record) error {
+	var b strings.Builder
+	if w.written {
+		b.WriteString("\n")
+	}
+
+	first := AI
+	if r.IsOpponentFirst {
+		first = Opponent
+	}
+
+	writeHeader(&b, "Trump", r.TrumpCard.notation())
+	writeHeader(&b, "First", first.String())
+	writeHeader(&b, "Hand", cardsNotation(r.Hand.cards))
+	if r.OpponentHand != nil {
+		writeHeader(&b, "OpponentHand", cardsNotation(r.OpponentHand.cards))
+	}
+	for _, rule := range ruleHeaders(&r.Rules) {
+		writeHeader(&b, rule.name, rule.get())
+	}
+
+	result := "*"
+	if r.Winner != Nobody {
+		result = fmt.Sprintf("%s %d", r.Winner, r.GamePoints)
+	}
+	writeHeader(&b, "Result", result)
+
+	trick := 0
+	isTrickOver := true
+	for _, event := range r.Events {
+		switch event.Kind {
+		case EventMove:
+			if isTrickOver {
+				trick++
+				fmt.Fprintf(&b, "\n%d.", trick)
+				isTrickOver = false
+			}
+			b.WriteString(" " + moveNotation(event.Move, r.Rules.AnnouncementPoints(event.Move.Card, r.TrumpCard.Suit)))
+		case EventTrick:
+			isTrickOver = true
+		case EventDraw:
+			b.WriteString(" (" + event.Card.notation() + ")")
+		}
+	}
+	if trick > 0 {
+		b.WriteString("\n")
+	}
+
+	w.written = true
+	_, err := io.WriteString(w.w, b.String())
+	return err
+}
+
+func writeHeader(b *strings.Builder, name string, value string) {
+	fmt.Fprintf(b, "[%s %s]\n", name, strconv.Quote(value))
+}
+
+// ruleHeader is a header used for a field of Rules. Value
+// points to the field (either a *bool or an *int).
+type ruleHeader struct {
+	name  string
+	value interface{}
+}
+
+func ruleHeaders(r *Rules) []ruleHeader {
+	return []ruleHeader{
+		{"AnnounceOnFirstTrick", &r.AnnounceOnFirstTrick},
+		{"SwitchWhenResponding", &r.SwitchWhenResponding},
+		{"TargetScore", &r.TargetScore},
+		{"MarriagePoints", &r.MarriagePoints},
+		{"TrumpMarriagePoints", &r.TrumpMarriagePoints},
+		{"LastTrickBonus", &r.LastTrickBonus},
+		{"DeferAnnouncements", &r.DeferAnnouncements},
+	}
+}
+
+func (h ruleHeader) get() string {
+	switch value := h.value.(type) {
+	case *bool:
+		return strconv.FormatBool(*value)
+	case *int:
+		return strconv.Itoa(*value)
+	}
+	return ""
+}
+
+func (h ruleHeader) set(str string) (err error) {
+	switch value := h.value.(type) {
+	case *bool:
+		*value, err = strconv.ParseBool(str)
+	case *int:
+		*value, err = strconv.Atoi(str)
+	}
+	return err
+}
+
+// notation returns the card in the notation of Card.MarshalText.
+func (c Card) notation() string {
+	text, err := c.MarshalText()
+	if err != nil {
+		return c.String()
+	}
+	return string(text)
+}
+
+func cardsNotation(cards CardSet) string {
+	var result []string
+	for _, card := range cards.Cards() {
+		result = append(result, card.notation())
+	}
+	return strings.Join(result, " ")
+}
+
+// moveNotation returns the move in the notation accepted by ParseMove.
+// points are written after the card of an announcement.
+func moveNotation(move Move, points int) string {
+	if move.Declare && !move.IsAnnouncement {
+		return "declare"
+	}
+
+	result := move.Card.notation()
+	if move.IsAnnouncement {
+		result += fmt.Sprintf("+%d", points)
+	}
+	if move.SwitchTrumpCard {
+		result += "+switch"
+	}
+	if move.CloseGame {
+		result += "+close"
+	}
+	if move.Declare {
+		result += "+declare"
+	}
+	return result
+}
+
+// ParseError is returned by Parser when a record cannot be read or
+// replayed. Line is the number of the line (starting from 1) the
+// error was found on.
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Parser reads records in the santase notation (see Record).
+type Parser struct {
+	scanner *bufio.Scanner
+	line    int
+	peeked  *string
+}
+
+// NewParser creates a Parser that reads from r.
+func NewParser(r io.Reader) *Parser {
+	return &Parser{scanner: bufio.NewScanner(r)}
+}
+
+var headerRegexp = regexp.MustCompile(`^\[(\w+)\s+(".*")\]$`)
+
+// Parse reads the next record and replays it with a new Game through
+// CreateGameWithRules, GetMove, UpdateOpponentMove and UpdateDrawnCard.
+// The returned game is in the state reached at the end of the record
+// and has no agent set.
+//
+// If a line of the record cannot be parsed, a move in it is illegal or
+// the result of the replayed game differs from the Result header a
+// *ParseError is returned. When the record has an OpponentHand header,
+// the hands must not share cards with each other or the trump card and
+// the opponent can only play cards dealt to them or drawn later. If
+// there are no more records io.EOF is returned.
+func (p *Parser) Parse() (Record, *Game, error) {
+	record := Record{Rules: StandardRules}
+	headers := make(map[string]string)
+	lines := make(map[string]int)
+
+	// headers
+	for {
+		line, ok := p.next()
+		if !ok {
+			if len(headers) == 0 {
+				return record, nil, p.err(io.EOF)
+			}
+			break
+		}
+		if line == "" {
+			if len(headers) == 0 {
+				continue
+			}
+			break
+		}
+
+		match := headerRegexp.FindStringSubmatch(line)
+		if match == nil {
+			if len(headers) == 0 {
+				return record, nil, p.errorf("expected a header, got %q", line)
+			}
+			p.unread(line)
+			break
+		}
+
+		value, err := strconv.Unquote(match[2])
+		if err != nil {
+			return record, nil, p.errorf("invalid header value %s", match[2])
+		}
+		headers[match[1]] = value
+		lines[match[1]] = p.line
+
+		if err := p.setHeader(&record, match[1], value); err != nil {
+			return record, nil, err
+		}
+	}
+
+	for _, name := range []string{"Trump", "First", "Hand"} {
+		if _, ok := headers[name]; !ok {
+			return record, nil, p.errorf("missing %s header", name)
+		}
+	}
+
+	if record.Hand.Len() != 6 {
+		return record, nil, p.errorf("hand does not have 6 cards")
+	}
+	if record.Hand.HasCard(record.TrumpCard) {
+		return record, nil, &ParseError{lines["Hand"], fmt.Errorf("hand has the trump card %s", record.TrumpCard)}
+	}
+
+	var opponent *opponentCards
+	if record.OpponentHand != nil {
+		if err := checkOpponentHand(record); err != nil {
+			return record, nil, &ParseError{lines["OpponentHand"], err}
+		}
+		opponent = &opponentCards{hand: record.OpponentHand.Clone()}
+	}
+
+	game := CreateGameWithRules(record.Hand.Clone(), record.TrumpCard, record.IsOpponentFirst, record.Rules)
+
+	// moves
+	trick := 0
+	for {
+		line, ok := p.next()
+		if !ok {
+			break
+		}
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			p.unread(line)
+			break
+		}
+
+		fields := strings.Fields(line)
+		trick++
+		if fields[0] != strconv.Itoa(trick)+"." {
+			return record, nil, p.errorf("expected trick %d., got %q", trick, fields[0])
+		}
+
+		for _, token := range fields[1:] {
+			if err := p.replay(&game, token, record, opponent); err != nil {
+				return record, nil, err
+			}
+		}
+	}
+	if err := p.scanner.Err(); err != nil {
+		return record, nil, err
+	}
+
+	record.Events = game.History()
+	game.SetAgent(dummyAgent{})
+
+	if result, ok := headers["Result"]; ok && result != "*" {
+		var winner Player
+		fields := strings.Fields(result)
+		if len(fields) != 2 || winner.UnmarshalText([]byte(fields[0])) != nil {
+			return record, nil, &ParseError{lines["Result"], fmt.Errorf("invalid result %q", result)}
+		}
+		points, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return record, nil, &ParseError{lines["Result"], fmt.Errorf("invalid result %q", result)}
+		}
+		if winner != game.Winner() || points != game.GamePoints() {
+			return record, nil, &ParseError{lines["Result"], fmt.Errorf(
+				"result %q does not match the game: %s %d", result, game.Winner(), game.GamePoints())}
+		}
+	}
+	record.Winner = game.Winner()
+	record.GamePoints = game.GamePoints()
+
+	return record, &game, nil
+}
+
+func (p *Parser) setHeader(record *Record, name string, value string) error {
+	switch name {
+	case "Trump":
+		if err := record.TrumpCard.UnmarshalText([]byte(value)); err != nil {
+			return p.err(err)
+		}
+	case "First":
+		var first Player
+		if err := first.UnmarshalText([]byte(value)); err != nil || first == Nobody {
+			return p.errorf("invalid first player %q", value)
+		}
+		record.IsOpponentFirst = first == Opponent
+	case "Hand", "OpponentHand":
+		hand := NewHand()
+		for _, field := range strings.Fields(value) {
+			var card Card
+			if err := card.UnmarshalText([]byte(field)); err != nil {
+				return p.err(err)
+			}
+			if hand.HasCard(card) || hand.Len() == 6 {
+				return p.errorf("invalid hand %q", value)
+			}
+			hand.AddCard(card)
+		}
+		if name == "Hand" {
+			record.Hand = hand
+		} else {
+			record.OpponentHand = &hand
+		}
+	default:
+		for _, rule := range ruleHeaders(&record.Rules) {
+			if rule.name == name {
+				if err := rule.set(value); err != nil {
+					return p.errorf("invalid %s %q", name, value)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// replayAgent plays the move read from a record.
+type replayAgent struct {
+	move Move
+}
+
+func (a replayAgent) GetMove(g *Game) Move {
+	return a.move
+}
+
+// checkOpponentHand checks that the hand dealt to the opponent
+// is complete and does not overlap the cards dealt to the AI.
+func checkOpponentHand(record Record) error {
+	opponentHand := record.OpponentHand.cards
+	if opponentHand.Len() != 6 {
+		return errors.New("opponent hand does not have 6 cards")
+	}
+	if !opponentHand.Intersect(record.Hand.cards).IsEmpty() {
+		return errors.New("opponent hand has cards of the hand")
+	}
+	if opponentHand.Has(record.TrumpCard) {
+		return fmt.Errorf("opponent hand has the trump card %s", record.TrumpCard)
+	}
+	return nil
+}
+
+// opponentCards follows the cards the opponent can hold while a record
+// with an OpponentHand header is replayed: the cards known to be in
+// their hand (dealt to them or revealed by their moves) and how many
+// cards they drew that the record has not revealed yet.
+type opponentCards struct {
+	hand  Hand
+	drawn int
+}
+
+// reveal checks that the opponent holds the card. A card not
+// known to be in their hand must be one of the cards they drew.
+func (o *opponentCards) reveal(card Card) error {
+	if o.hand.HasCard(card) {
+		return nil
+	}
+	if o.drawn == 0 {
+		return fmt.Errorf("opponent does not have %s", card)
+	}
+	o.drawn--
+	o.hand.AddCard(card)
+	return nil
+}
+
+// play checks that the opponent can play the move and removes the
+// cards that leave their hand with it.
+func (o *opponentCards) play(move Move, trumpCard *Card) error {
+	if move.Declare && !move.IsAnnouncement {
+		return nil
+	}
+
+	if move.SwitchTrumpCard && trumpCard != nil {
+		nineTrump := NewCard(Nine, trumpCard.Suit)
+		if err := o.reveal(nineTrump); err != nil {
+			return err
+		}
+		o.hand.RemoveCard(nineTrump)
+		o.hand.AddCard(*trumpCard)
+	}
+
+	if err := o.reveal(move.Card); err != nil {
+		return err
+	}
+	if move.IsAnnouncement {
+		if err := o.reveal(marriagePartner(move.Card)); err != nil {
+			return err
+		}
+	}
+
+	if !move.Declare {
+		o.hand.RemoveCard(move.Card)
+	}
+	return nil
+}
+
+// draw updates the cards of the opponent after a trick in which
+// they drew a card. The card is revealed only when it is the trump
+// card, which is drawn last by the player that lost the trick.
+func (o *opponentCards) draw(game *Game) {
+	if game.seenCards.Len() == 12 && !game.isOpponentMove {
+		o.hand.AddCard(*game.trumpCard)
+	} else {
+		o.drawn++
+	}
+}
+
+func (p *Parser) replay(game *Game, token string, record Record, opponent *opponentCards) error {
+	if strings.HasPrefix(token, "(") && strings.HasSuffix(token, ")") {
+		var card Card
+		if err := card.UnmarshalText([]byte(token[1 : len(token)-1])); err != nil {
+			return p.err(err)
+		}
+		return p.err(game.TryUpdateDrawnCard(card))
+	}
+
+	move, err := parseMoveNotation(token, record.Rules, record.TrumpCard.Suit)
+	if err != nil {
+		return p.err(err)
+	}
+
+	// the players draw after the trick if the move completes
+	// it and there are cards to draw
+	draws := game.cardPlayed != nil && game.trumpCard != nil && !game.isClosed
+
+	if game.IsOpponentMove() {
+		if opponent != nil {
+			if err := opponent.play(move, game.trumpCard); err != nil {
+				return p.err(err)
+			}
+		}
+		err = game.TryUpdateOpponentMove(move)
+	} else {
+		game.SetAgent(replayAgent{move})
+		_, err = game.TryGetMove()
+	}
+	if err != nil {
+		return p.err(err)
+	}
+
+	if opponent != nil && draws {
+		opponent.draw(game)
+	}
+	return nil
+}
+
+func parseMoveNotation(token string, rules Rules, trump Suit) (Move, error) {
+	move, points, err := parseMove(token)
+	if err != nil {
+		return move, err
+	}
+
+	if move.IsAnnouncement && points != rules.AnnouncementPoints(move.Card, trump) {
+		return move, fmt.Errorf("announcement of %s is not worth %d", move.Card, points)
+	}
+	return move, nil
+}
+
+func (p *Parser) next() (string, bool) {
+	if p.peeked != nil {
+		line := *p.peeked
+		p.peeked = nil
+		return line, true
+	}
+
+	for p.scanner.Scan() {
+		p.line++
+		line := strings.TrimSpace(p.scanner.Text())
+		if strings.HasPrefix(line, ";") {
+			continue
+		}
+		return line, true
+	}
+	return "", false
+}
+
+// unread returns the line to be read again by the next call to next.
+func (p *Parser) unread(line string) {
+	p.peeked = &line
+}
+
+func (p *Parser) err(err error) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	return &ParseError{p.line, err}
+}
+
+func (p *Parser) errorf(format string, args ...interface{}) error {
+	return p.err(fmt.Errorf(format, args...))
+}
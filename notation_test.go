@@ -0,0 +1,145 @@
+package santase
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleRecord = `; a deal from the history test
+[Trump "10C"]
+[First "opponent"]
+[Hand "9D QD 10H 9S KS AS"]
+[Event "test"]
+
+1. JS AS (JH)
+2. 10H+close AH
+3. QH+20 JH
+`
+
+func TestParser(t *testing.T) {
+	parser := NewParser(strings.NewReader(sampleRecord))
+	record, game, err := parser.Parse()
+	assert.Nil(t, err)
+
+	assert.Equal(t, NewCard(Ten, Clubs), record.TrumpCard)
+	assert.True(t, record.IsOpponentFirst)
+	assert.Equal(t, createSampleHand(), record.Hand)
+	assert.Nil(t, record.OpponentHand)
+	assert.Equal(t, StandardRules, record.Rules)
+	assert.Equal(t, Nobody, record.Winner)
+
+	assert.Equal(t, 13, game.GetScore())
+	assert.Equal(t, 46, game.GetOpponentScore())
+	assert.True(t, game.IsClosed())
+	assert.True(t, game.IsOpponentMove())
+	assert.Equal(t, game.History(), record.Events)
+
+	_, _, err = parser.Parse()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	table := NewTable(lowestCardAgent{}, lowestCardAgent{})
+	rules := StandardRules
+	rules.AnnounceOnFirstTrick = true
+	table.SetRules(rules)
+
+	var b bytes.Buffer
+	writer := NewWriter(&b)
+	var results []DealResult
+	for i := 0; i < 10; i++ {
+		result, err := table.Play()
+		assert.Nil(t, err)
+		assert.Nil(t, writer.WriteRecord(result.Record))
+		results = append(results, result)
+	}
+	text := b.String()
+
+	parser := NewParser(strings.NewReader(text))
+	var rewritten bytes.Buffer
+	rewriter := NewWriter(&rewritten)
+	for _, result := range results {
+		record, game, err := parser.Parse()
+		assert.Nil(t, err)
+		assert.Equal(t, result.Record, record)
+		assert.Equal(t, result.Scores[0], game.GetScore())
+		assert.True(t, game.IsOver())
+		assert.Nil(t, rewriter.WriteRecord(record))
+	}
+
+	_, _, err := parser.Parse()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, text, rewritten.String())
+}
+
+func TestWriteGame(t *testing.T) {
+	_, game, err := NewParser(strings.NewReader(sampleRecord)).Parse()
+	assert.Nil(t, err)
+
+	var b bytes.Buffer
+	assert.Nil(t, NewWriter(&b).WriteGame(game))
+	assert.Equal(t, `[Trump "10C"]
+[First "opponent"]
+[Hand "9D QD 10H 9S KS AS"]
+[AnnounceOnFirstTrick "false"]
+[SwitchWhenResponding "false"]
+[TargetScore "66"]
+[MarriagePoints "20"]
+[TrumpMarriagePoints "40"]
+[LastTrickBonus "10"]
+[DeferAnnouncements "true"]
+[Result "*"]
+
+1. JS AS (JH)
+2. 10H+close AH
+3. QH+20 JH
+`, b.String())
+}
+
+func TestParserErrors(t *testing.T) {
+	headers := "[Trump \"10C\"]\n[First \"opponent\"]\n[Hand \"9D QD 10H 9S KS AS\"]\n\n"
+	opponentHand := func(cards string) string {
+		return strings.Replace(headers, "\n\n", "\n[OpponentHand \""+cards+"\"]\n\n", 1)
+	}
+
+	tests := []struct {
+		name string
+		text string
+		line int
+		err  error
+	}{
+		{"missing header", "[Trump \"10C\"]\n[Hand \"9D QD 10H 9S KS AS\"]\n", 2, nil},
+		{"invalid card", "[Trump \"10X\"]\n", 1, ErrInvalidCard},
+		{"not a header", "1. JS AS\n", 1, nil},
+		{"illegal move", headers + "1. JS AS (JH)\n2. JD AH\n", 6, ErrCardNotInHand},
+		{"illegal draw", headers + "1. JS AS (AS)\n", 5, ErrDrawnCardPlayed},
+		{"wrong trick number", headers + "1. JS AS (JH)\n3. 10H AH\n", 6, nil},
+		{"wrong announcement", headers + "1. JS AS (KD)\n2. QD+40 AH\n", 6, nil},
+		{"invalid annotation", headers + "1. JS+foo AS\n", 5, nil},
+		{"hand with trump card", "[Trump \"10C\"]\n[First \"opponent\"]\n[Hand \"9D QD 10C 9S KS AS\"]\n", 3, nil},
+		{"incomplete opponent hand", opponentHand("JS QS AH JH 9C"), 4, nil},
+		{"opponent hand overlapping hand", opponentHand("JS QS AH JH 9C 9D"), 4, nil},
+		{"opponent hand with trump card", opponentHand("JS QS AH JH 9C 10C"), 4, nil},
+		{"opponent move not in opponent hand", opponentHand("JS QS AH JH 9C AD") + "1. KH AS\n", 6, nil},
+		{"opponent announcement not in opponent hand", "[AnnounceOnFirstTrick \"true\"]\n" + opponentHand("JS QH AH JH 9C AD") + "1. QH+20 10H\n", 7, nil},
+		{"wrong result", strings.Replace(headers, "\n\n", "\n[Result \"ai 1\"]\n\n", 1) + "1. JS AS (JH)\n2. declare\n", 4, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := NewParser(strings.NewReader(test.text)).Parse()
+			var parseErr *ParseError
+			if assert.True(t, errors.As(err, &parseErr), "%v", err) {
+				assert.Equal(t, test.line, parseErr.Line)
+			}
+			if test.err != nil {
+				assert.True(t, errors.Is(err, test.err), "%v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package santase
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseCard parses a card written as its rank followed by its suit.
+//
+// The rank is one of 9, J, Q, K, 10 (or T) and A and the suit is either
+// a letter (C, D, H or S) or a symbol (♣, ♦, ♥ or ♠). Letters can be in
+// any case, so "10♥" (the output of Card.String), "10H", "TH" and "th"
+// are all the ten of hearts.
+func ParseCard(str string) (Card, error) {
+	runes := []rune(str)
+	if len(runes) < 2 {
+		return Card{}, fmt.Errorf("%w: %q is too short", ErrInvalidCard, str)
+	}
+
+	rankString := string(runes[:len(runes)-1])
+	rank, ok := parseRank(rankString)
+	if !ok {
+		return Card{}, fmt.Errorf("%w: %q has unknown rank %q", ErrInvalidCard, str, rankString)
+	}
+
+	suitString := string(runes[len(runes)-1:])
+	suit, ok := parseSuit(suitString)
+	if !ok {
+		return Card{}, fmt.Errorf("%w: %q has unknown suit %q", ErrInvalidCard, str, suitString)
+	}
+
+	return NewCard(rank, suit), nil
+}
+
+// ParseHand parses a hand written as a list of cards (see ParseCard)
+// separated by spaces or commas. The list can be surrounded by braces,
+// so the output of Hand.String is accepted as well as "9D QD 10H".
+//
+// An error is returned if a card is invalid, is repeated or if there
+// are more than 6 cards.
+func ParseHand(str string) (Hand, error) {
+	str = strings.TrimSpace(str)
+	if strings.HasPrefix(str, "{") && strings.HasSuffix(str, "}") {
+		str = str[1 : len(str)-1]
+	}
+
+	fields := strings.FieldsFunc(str, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	if len(fields) > 6 {
+		return Hand{}, fmt.Errorf("hand %q has more than 6 cards", str)
+	}
+
+	hand := NewHand()
+	for _, field := range fields {
+		card, err := ParseCard(field)
+		if err != nil {
+			return Hand{}, err
+		}
+		if hand.HasCard(card) {
+			return Hand{}, fmt.Errorf("hand %q has %s more than once", str, card)
+		}
+		hand.AddCard(card)
+	}
+	return hand, nil
+}
+
+// ParseMove parses a move written as a card (see ParseCard) followed by
+// annotations separated by "+" or spaces: the points of an announcement
+// (e.g. "Qs+40"), "switch", "close" and "declare" (in any case). A
+// declaration without an announcement is written as just "declare".
+//
+// For example "9c switch" plays the nine of clubs after switching the
+// trump card and "QH+20+declare" declares with the announcement of the
+// marriage of hearts.
+//
+// The points of an announcement must be the points of a marriage in
+// StandardRules (20 or 40). The trump suit is not known, so either of
+// them is accepted for any marriage.
+func ParseMove(str string) (Move, error) {
+	move, points, err := parseMove(str)
+	if err != nil {
+		return Move{}, err
+	}
+
+	if move.IsAnnouncement && points != StandardRules.MarriagePoints && points != StandardRules.TrumpMarriagePoints {
+		return Move{}, fmt.Errorf("move %q announces a marriage for %d points", str, points)
+	}
+	return move, nil
+}
+
+// parseMove parses a move like ParseMove and returns
+// the points written for the announcement.
+func parseMove(str string) (Move, int, error) {
+	fields := strings.FieldsFunc(str, func(r rune) bool {
+		return r == '+' || unicode.IsSpace(r)
+	})
+	if len(fields) == 0 {
+		return Move{}, 0, errors.New("empty move")
+	}
+
+	if strings.EqualFold(fields[0], "declare") && len(fields) == 1 {
+		return Move{Declare: true}, 0, nil
+	}
+
+	card, err := ParseCard(fields[0])
+	if err != nil {
+		return Move{}, 0, err
+	}
+
+	move := Move{Card: card}
+	points := 0
+	for _, annotation := range fields[1:] {
+		switch strings.ToLower(annotation) {
+		case "switch":
+			move.SwitchTrumpCard = true
+		case "close":
+			move.CloseGame = true
+		case "declare":
+			move.Declare = true
+		default:
+			points, err = strconv.Atoi(annotation)
+			if err != nil || points <= 0 || move.IsAnnouncement {
+				return Move{}, 0, fmt.Errorf("move %q has invalid annotation %q", str, annotation)
+			}
+			if card.Rank != Queen && card.Rank != King {
+				return Move{}, 0, fmt.Errorf("move %q announces with %s, which is not a queen or a king", str, card)
+			}
+			move.IsAnnouncement = true
+		}
+	}
+
+	if move.Declare && !move.IsAnnouncement {
+		return Move{}, 0, fmt.Errorf("move %q declares while playing a card (only announcements can be combined with declaring)", str)
+	}
+	return move, points, nil
+}
+
+func parseSuit(str string) (Suit, bool) {
+	for suit, letter := range suitLetters {
+		if strings.EqualFold(str, letter) || str == suitStrings[suit] {
+			return suit, true
+		}
+	}
+	return 0, false
+}
+
+func parseRank(str string) (Rank, bool) {
+	if strings.EqualFold(str, "T") {
+		return Ten, true
+	}
+
+	for rank, rankString := range rankStrings {
+		if strings.EqualFold(str, rankString) {
+			return rank, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,80 @@
+package santase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCard(t *testing.T) {
+	for _, str := range []string{"10♥", "10H", "10h", "TH", "th", "T♥"} {
+		card, err := ParseCard(str)
+		assert.Nil(t, err, str)
+		assert.Equal(t, NewCard(Ten, Hearts), card, str)
+	}
+
+	for _, card := range AllCards {
+		parsed, err := ParseCard(card.String())
+		assert.Nil(t, err)
+		assert.Equal(t, card, parsed)
+	}
+
+	for _, str := range []string{"", "H", "1H", "10X", "QH+20", "10 H"} {
+		_, err := ParseCard(str)
+		assert.True(t, errors.Is(err, ErrInvalidCard), str)
+	}
+}
+
+func TestParseHand(t *testing.T) {
+	hand := createSampleHand()
+
+	for _, str := range []string{hand.String(), "9D QD 10H 9S KS AS", "9d,qd, th ,9s ks as", "{9D QD 10H 9S KS AS}"} {
+		parsed, err := ParseHand(str)
+		assert.Nil(t, err, str)
+		assert.Equal(t, hand, parsed, str)
+	}
+
+	parsed, err := ParseHand("{ }")
+	assert.Nil(t, err)
+	assert.Equal(t, NewHand(), parsed)
+
+	_, err = ParseHand("9D QD XX")
+	assert.True(t, errors.Is(err, ErrInvalidCard))
+
+	_, err = ParseHand("9D QD 9d")
+	assert.NotNil(t, err)
+
+	_, err = ParseHand("9D QD 10H 9S KS AS JC")
+	assert.NotNil(t, err)
+}
+
+func TestParseMove(t *testing.T) {
+	tests := []struct {
+		str  string
+		move Move
+	}{
+		{"AS", Move{Card: NewCard(Ace, Spades)}},
+		{"Q♠+40", Move{Card: NewCard(Queen, Spades), IsAnnouncement: true}},
+		{"Qs+40", Move{Card: NewCard(Queen, Spades), IsAnnouncement: true}},
+		{"kh 20", Move{Card: NewCard(King, Hearts), IsAnnouncement: true}},
+		{"9c switch", Move{Card: NewCard(Nine, Clubs), SwitchTrumpCard: true}},
+		{"9C+switch+close", Move{Card: NewCard(Nine, Clubs), SwitchTrumpCard: true, CloseGame: true}},
+		{"QH+20+DECLARE", Move{Card: NewCard(Queen, Hearts), IsAnnouncement: true, Declare: true}},
+		{"declare", Move{Declare: true}},
+	}
+
+	for _, test := range tests {
+		move, err := ParseMove(test.str)
+		assert.Nil(t, err, test.str)
+		assert.Equal(t, test.move, move, test.str)
+	}
+
+	_, err := ParseMove("XS")
+	assert.True(t, errors.Is(err, ErrInvalidCard))
+
+	for _, str := range []string{"", "AS+foo", "AS+20", "QS+0", "QS+7", "KH 60", "QS+20+40", "AS declare"} {
+		_, err := ParseMove(str)
+		assert.NotNil(t, err, str)
+	}
+}
@@ -0,0 +1,228 @@
+package santase
+
+// Rules contains the variations of the rules of santase a Game can
+// be played with. StandardRules contains the rules used by default.
+//
+// AnnounceOnFirstTrick allows announcing a marriage on the first trick
+// of the game. SwitchWhenResponding allows switching the trump card with
+// the nine of trump when responding to a card played by the opponent and
+// not only when playing first. TargetScore is the number of points a
+// player needs to collect to win the game. MarriagePoints and
+// TrumpMarriagePoints are the points an announcement of a marriage
+// brings in a suit different from the trump suit and in the trump suit.
+// LastTrickBonus is the number of extra points the winner of the last
+// trick earns when all the cards have been drawn from the stack (it is
+// not awarded when the game is closed). DeferAnnouncements makes the
+// points of an announcement count only once the announcing player has
+// won a trick, until then they are pending.
+type Rules struct {
+	AnnounceOnFirstTrick bool `json:"announceOnFirstTrick"`
+	SwitchWhenResponding bool `json:"switchWhenResponding"`
+	TargetScore          int  `json:"targetScore"`
+	MarriagePoints       int  `json:"marriagePoints"`
+	TrumpMarriagePoints  int  `json:"trumpMarriagePoints"`
+	LastTrickBonus       int  `json:"lastTrickBonus"`
+	DeferAnnouncements   bool `json:"deferAnnouncements"`
+}
+
+// StandardRules are the rules of santase used by CreateGame.
+var StandardRules = Rules{
+	AnnounceOnFirstTrick: false,
+	SwitchWhenResponding: false,
+	TargetScore:          66,
+	MarriagePoints:       20,
+	TrumpMarriagePoints:  40,
+	LastTrickBonus:       10,
+	DeferAnnouncements:   true,
+}
+
+// AnnouncementPoints returns the points an announcement of the marriage
+// the passed card is part of brings in a game with a particular trump.
+func (r Rules) AnnouncementPoints(c Card, trump Suit) int {
+	if c.Suit == trump {
+		return r.TrumpMarriagePoints
+	}
+	return r.MarriagePoints
+}
+
+// position is the part of a deal the rules of a move depend on: what
+// both players know about the deal and the cards the player to move
+// holds (or, for the opponent of the AI, may hold). Game and State check
+// moves through it, so the rules are implemented in one place.
+//
+// tricks is the number of tricks played so far.
+type position struct {
+	rules      Rules
+	trump      Suit
+	trumpCard  *Card
+	cardPlayed *Card
+	isClosed   bool
+	tricks     int
+	hand       Hand
+}
+
+// validateMove checks a move of the player to move. It does not check
+// whether it is their turn or whether the deal is over.
+func (p position) validateMove(move Move) error {
+	if move.Declare {
+		return p.validateDeclaration(move)
+	}
+
+	hand := p.hand
+	if move.SwitchTrumpCard {
+		if err := p.validateSwitch(); err != nil {
+			return err
+		}
+		hand = p.switchedHand()
+	}
+
+	if move.CloseGame {
+		if err := p.validateClose(); err != nil {
+			return err
+		}
+	}
+
+	if move.IsAnnouncement {
+		if err := p.validateAnnouncement(move.Card, hand); err != nil {
+			return err
+		}
+	}
+
+	if !hand.HasCard(move.Card) {
+		return ErrCardNotInHand
+	}
+
+	if p.cardPlayed != nil && (p.isClosed || p.trumpCard == nil) {
+		possibleResponses := hand.GetValidResponses(*p.cardPlayed, p.trump)
+		if !possibleResponses.HasCard(move.Card) {
+			return &IllegalResponseError{
+				Card:     move.Card,
+				Played:   *p.cardPlayed,
+				Legal:    possibleResponses,
+				IsClosed: p.isClosed,
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p position) validateSwitch() error {
+	if p.cardPlayed != nil && !p.rules.SwitchWhenResponding {
+		return ErrSwitchNotFirst
+	}
+
+	if p.tricks == 0 {
+		return ErrSwitchOnFirstMove
+	}
+
+	if p.tricks == 5 {
+		return ErrSwitchTwoCardsLeft
+	}
+
+	if p.trumpCard == nil {
+		return ErrSwitchTrumpTaken
+	}
+
+	if p.isClosed {
+		return ErrSwitchClosed
+	}
+
+	if p.trumpCard.Rank == Nine {
+		return ErrSwitchTrumpIsNine
+	}
+
+	if !p.hand.HasCard(NewCard(Nine, p.trump)) {
+		return ErrSwitchWithoutNine
+	}
+
+	return nil
+}
+
+// switchedHand returns the hand after switching
+// the trump card with the nine of trump.
+func (p position) switchedHand() Hand {
+	nineTrump := NewCard(Nine, p.trump)
+	return Hand{p.hand.cards.Difference(nineTrump.bit()).Union(p.trumpCard.bit())}
+}
+
+func (p position) validateClose() error {
+	if p.cardPlayed != nil {
+		return ErrCloseNotFirst
+	}
+
+	if p.tricks == 0 {
+		return ErrCloseOnFirstMove
+	}
+
+	if p.tricks == 5 {
+		return ErrCloseTwoCardsLeft
+	}
+
+	if p.tricks >= 6 {
+		return ErrCloseAllDrawn
+	}
+
+	if p.isClosed {
+		return ErrAlreadyClosed
+	}
+
+	return nil
+}
+
+func (p position) validateAnnouncement(card Card, hand Hand) error {
+	if p.cardPlayed != nil {
+		return ErrAnnounceNotFirst
+	}
+
+	if p.tricks == 0 && !p.rules.AnnounceOnFirstTrick {
+		return ErrAnnounceOnFirstMove
+	}
+
+	if !canAnnounceWith(card) {
+		return invalidAnnouncementCard(card)
+	}
+
+	if !hand.HasCard(marriagePartner(card)) {
+		return ErrMarriageNotInHand
+	}
+
+	return nil
+}
+
+// validateDeclaration checks a move declaring that the player has
+// collected enough points to win. The declaration can be made together
+// with an announcement, in which case the announced card has to be in
+// hand.
+func (p position) validateDeclaration(move Move) error {
+	if p.cardPlayed != nil {
+		return ErrDeclareNotFirst
+	}
+
+	if move.SwitchTrumpCard || move.CloseGame {
+		return ErrInvalidDeclaration
+	}
+
+	if move.IsAnnouncement {
+		if err := p.validateAnnouncement(move.Card, p.hand); err != nil {
+			return err
+		}
+
+		if !p.hand.HasCard(move.Card) {
+			return ErrCardNotInHand
+		}
+	}
+
+	return nil
+}
+
+// cards returns the cards the player to move can play:
+// the cards in hand and the trump card, which can be
+// played after switching it.
+func (p position) cards() CardSet {
+	cards := p.hand.cards
+	if p.trumpCard != nil {
+		cards.Add(*p.trumpCard)
+	}
+	return cards
+}
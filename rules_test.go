@@ -0,0 +1,281 @@
+package santase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnouncementPoints(t *testing.T) {
+	rules := Rules{MarriagePoints: 30, TrumpMarriagePoints: 60}
+
+	assert.Equal(t, 20, StandardRules.AnnouncementPoints(NewCard(King, Hearts), Spades))
+	assert.Equal(t, 40, StandardRules.AnnouncementPoints(NewCard(Queen, Spades), Spades))
+	assert.Equal(t, 30, rules.AnnouncementPoints(NewCard(King, Hearts), Spades))
+	assert.Equal(t, 60, rules.AnnouncementPoints(NewCard(Queen, Spades), Spades))
+}
+
+func TestCreateGameWithRules(t *testing.T) {
+	game := CreateGame(createSampleHand(), NewCard(Ten, Clubs), true)
+	assert.Equal(t, StandardRules, game.GetRules())
+
+	rules := StandardRules
+	rules.TargetScore = 51
+	game = CreateGameWithRules(createSampleHand(), NewCard(Ten, Clubs), true, rules)
+	assert.Equal(t, rules, game.GetRules())
+	assert.Equal(t, 51, game.GetTally().TargetScore)
+}
+
+func TestAnnounceOnFirstTrick(t *testing.T) {
+	move := Move{Card: NewCard(Queen, Hearts), IsAnnouncement: true}
+
+	t.Run("standard rules", func(t *testing.T) {
+		game := createSampleGame()
+
+		err := game.TryUpdateOpponentMove(move)
+		assert.Equal(t, ErrAnnounceOnFirstMove, err)
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		rules := StandardRules
+		rules.AnnounceOnFirstTrick = true
+		hand := NewHand(
+			NewCard(Queen, Hearts),
+			NewCard(King, Hearts),
+			NewCard(Nine, Diamonds),
+			NewCard(Ten, Spades),
+			NewCard(Ace, Spades),
+			NewCard(Jack, Clubs),
+		)
+		game := CreateGameWithRules(hand, NewCard(Ten, Clubs), false, rules)
+		game.SetAgent(fixedAgent{move})
+
+		_, err := game.TryGetMove()
+		assert.Nil(t, err)
+		assert.Equal(t, 0, game.GetScore())
+		assert.Equal(t, 20, game.GetPendingScore())
+	})
+}
+
+func TestSwitchWhenResponding(t *testing.T) {
+	rules := StandardRules
+	rules.SwitchWhenResponding = true
+	game := CreateGameWithRules(createSampleHand(), NewCard(Ten, Spades), false, rules)
+
+	// simulating playing one hand
+	game.seenCards.AddCard(NewCard(Jack, Diamonds))
+	game.unseenCards.RemoveCard(NewCard(Jack, Diamonds))
+	game.seenCards.AddCard(NewCard(Nine, Diamonds))
+	game.unseenCards.RemoveCard(NewCard(Nine, Diamonds))
+	game.hand.RemoveCard(NewCard(Nine, Diamonds))
+	game.hand.AddCard(NewCard(Jack, Hearts))
+	game.unseenCards.RemoveCard(NewCard(Jack, Hearts))
+	game.isOpponentMove = true
+
+	game.UpdateOpponentMove(Move{Card: NewCard(Ace, Hearts)})
+
+	move := Move{Card: NewCard(Ten, Spades), SwitchTrumpCard: true}
+	game.SetAgent(fixedAgent{move})
+
+	_, err := game.TryGetMove()
+	assert.Nil(t, err)
+	assert.Equal(t, NewCard(Nine, Spades), *game.GetTrumpCard())
+	assert.False(t, game.hand.HasCard(NewCard(Nine, Spades)))
+	assert.Equal(t, 21, game.GetScore())
+
+	game = CreateGameWithRules(createSampleHand(), NewCard(Ten, Spades), true, StandardRules)
+	game.seenCards.AddCard(NewCard(Jack, Diamonds))
+	game.unseenCards.RemoveCard(NewCard(Jack, Diamonds))
+	game.seenCards.AddCard(NewCard(Nine, Diamonds))
+	game.unseenCards.RemoveCard(NewCard(Nine, Diamonds))
+	game.UpdateOpponentMove(Move{Card: NewCard(Ace, Hearts)})
+	game.SetAgent(fixedAgent{move})
+
+	_, err = game.TryGetMove()
+	assert.Equal(t, ErrSwitchNotFirst, err)
+}
+
+func TestTargetScore(t *testing.T) {
+	rules := StandardRules
+	rules.TargetScore = 51
+	game := CreateGameWithRules(createSampleHand(), NewCard(Ten, Clubs), false, rules)
+	game.score = 55
+	game.tricks = 3
+	game.opponentScore = 20
+	game.opponentTricks = 1
+	game.seenCards.AddCard(NewCard(Jack, Diamonds))
+	game.seenCards.AddCard(NewCard(Ten, Diamonds))
+	game.SetAgent(fixedAgent{Move{Declare: true}})
+
+	_, err := game.TryGetMove()
+	assert.Nil(t, err)
+	assert.Equal(t, AI, game.Winner())
+	assert.Equal(t, 2, game.GamePoints())
+}
+
+func TestTableRules(t *testing.T) {
+	rules := StandardRules
+	rules.TargetScore = 51
+	table := NewTable(lowestCardAgent{}, lowestCardAgent{})
+	table.SetRules(rules)
+
+	match, _, err := table.PlayMatch(DealerRotation)
+	assert.Nil(t, err)
+	for _, deal := range match.Deals() {
+		assert.Equal(t, rules, deal.GetRules())
+	}
+}
+
+// createLastTrickGame creates a game where the opponent is about to lead
+// the last trick with the passed card and the AI has only the ace of
+// spades left.
+func createLastTrickGame(rules Rules, opponentCard Card) Game {
+	game := CreateGameWithRules(createSampleHand(), NewCard(Ten, Clubs), true, rules)
+	game.hand = NewHand(NewCard(Ace, Spades))
+	game.trumpCard = nil
+	game.unseenCards = NewPile()
+	game.unseenCards.AddCard(opponentCard)
+	game.seenCards = NewPile()
+	for _, card := range AllCards {
+		if !game.hand.HasCard(card) && !game.unseenCards.HasCard(card) {
+			game.seenCards.AddCard(card)
+		}
+	}
+	game.score = 40
+	game.tricks = 5
+	game.opponentScore = 50
+	game.opponentTricks = 6
+	return game
+}
+
+func TestLastTrickBonus(t *testing.T) {
+	t.Run("standard rules", func(t *testing.T) {
+		game := createLastTrickGame(StandardRules, NewCard(King, Spades))
+		game.SetAgent(fixedAgent{Move{Card: NewCard(Ace, Spades)}})
+		game.UpdateOpponentMove(Move{Card: NewCard(King, Spades)})
+		game.GetMove()
+
+		assert.Equal(t, 65, game.GetScore())
+		assert.True(t, game.IsOver())
+		assert.Equal(t, AI, game.Winner())
+		assert.Equal(t, 1, game.GamePoints())
+	})
+
+	t.Run("won by the opponent", func(t *testing.T) {
+		game := createLastTrickGame(StandardRules, NewCard(Nine, Clubs))
+		game.isOpponentMove = false
+		game.SetAgent(fixedAgent{Move{Card: NewCard(Ace, Spades)}})
+		game.GetMove()
+		game.UpdateOpponentMove(Move{Card: NewCard(Nine, Clubs)})
+
+		assert.Equal(t, 40, game.GetScore())
+		assert.Equal(t, 71, game.GetOpponentScore())
+		assert.Equal(t, Opponent, game.Winner())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		rules := StandardRules
+		rules.LastTrickBonus = 0
+		game := createLastTrickGame(rules, NewCard(King, Spades))
+		game.SetAgent(fixedAgent{Move{Card: NewCard(Ace, Spades)}})
+		game.UpdateOpponentMove(Move{Card: NewCard(King, Spades)})
+		game.GetMove()
+
+		assert.Equal(t, 55, game.GetScore())
+	})
+
+	t.Run("closed game", func(t *testing.T) {
+		game := createLastTrickGame(StandardRules, NewCard(King, Spades))
+		game.isClosed = true
+		game.closedBy = AI
+		game.SetAgent(fixedAgent{Move{Card: NewCard(Ace, Spades)}})
+		game.UpdateOpponentMove(Move{Card: NewCard(King, Spades)})
+		game.GetMove()
+
+		assert.Equal(t, 55, game.GetScore())
+	})
+}
+
+func TestTableLastTrickBonus(t *testing.T) {
+	table := NewTable(lowestCardAgent{}, lowestCardAgent{})
+	for i := 0; i < 20; i++ {
+		result, err := table.Play()
+		assert.Nil(t, err)
+
+		total := 0
+		for _, trick := range result.Tricks {
+			total += trick.Points
+		}
+		if len(result.Tricks) == 12 {
+			// all the cards were played
+			assert.Equal(t, 130, total)
+		}
+	}
+}
+
+func TestDeferAnnouncements(t *testing.T) {
+	rules := StandardRules
+	rules.AnnounceOnFirstTrick = true
+
+	// createGame creates a game where the AI announces
+	// the marriage of hearts on the first trick
+	createGame := func(rules Rules) Game {
+		hand := NewHand(
+			NewCard(Queen, Hearts),
+			NewCard(King, Hearts),
+			NewCard(Nine, Diamonds),
+			NewCard(Ten, Spades),
+			NewCard(Ace, Spades),
+			NewCard(Jack, Clubs),
+		)
+		game := CreateGameWithRules(hand, NewCard(Ten, Clubs), false, rules)
+		game.SetAgent(fixedAgent{Move{Card: NewCard(Queen, Hearts), IsAnnouncement: true}})
+		game.GetMove()
+		return game
+	}
+
+	t.Run("converted on the first won trick", func(t *testing.T) {
+		game := createGame(rules)
+		assert.Equal(t, 20, game.GetPendingScore())
+
+		game.UpdateOpponentMove(Move{Card: NewCard(Nine, Hearts)})
+		assert.Equal(t, 23, game.GetScore())
+		assert.Equal(t, 0, game.GetPendingScore())
+	})
+
+	t.Run("pending after a lost trick", func(t *testing.T) {
+		game := createGame(rules)
+		game.UpdateOpponentMove(Move{Card: NewCard(Ace, Hearts)})
+		game.UpdateDrawnCard(NewCard(Jack, Hearts))
+		assert.Equal(t, 0, game.GetScore())
+		assert.Equal(t, 20, game.GetPendingScore())
+		assert.Equal(t, 14, game.GetOpponentScore())
+
+		game.UpdateOpponentMove(Move{Card: NewCard(Nine, Hearts)})
+		game.SetAgent(fixedAgent{Move{Card: NewCard(King, Hearts)}})
+		game.GetMove()
+		assert.Equal(t, 24, game.GetScore())
+		assert.Equal(t, 0, game.GetPendingScore())
+	})
+
+	t.Run("opponent announcement", func(t *testing.T) {
+		game := CreateGameWithRules(createSampleHand(), NewCard(Ten, Clubs), true, rules)
+		game.UpdateOpponentMove(Move{Card: NewCard(King, Hearts), IsAnnouncement: true})
+		assert.Equal(t, 0, game.GetOpponentScore())
+		assert.Equal(t, 20, game.GetOpponentPendingScore())
+
+		game.SetAgent(fixedAgent{Move{Card: NewCard(Ten, Hearts)}})
+		game.GetMove()
+		assert.Equal(t, 14, game.GetScore())
+		assert.Equal(t, 0, game.GetOpponentScore())
+		assert.Equal(t, 20, game.GetOpponentPendingScore())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		rules := rules
+		rules.DeferAnnouncements = false
+		game := createGame(rules)
+		assert.Equal(t, 20, game.GetScore())
+		assert.Equal(t, 0, game.GetPendingScore())
+	})
+}
@@ -0,0 +1,149 @@
+package santase
+
+// Player identifies one of the two players in a game
+// from the point of view of the AI.
+type Player int
+
+// Nobody is used when no player is meant (e.g. there
+// is no winner yet), AI is the player the game is
+// created for and Opponent is the other player.
+const (
+	Nobody Player = iota
+	AI
+	Opponent
+)
+
+var playerStrings = map[Player]string{
+	Nobody:   "nobody",
+	AI:       "ai",
+	Opponent: "opponent",
+}
+
+func (p Player) String() string {
+	if str, ok := playerStrings[p]; ok {
+		return str
+	}
+	return "invalid"
+}
+
+// Other returns the other player. The other player of Nobody is Nobody.
+func (p Player) Other() Player {
+	switch p {
+	case AI:
+		return Opponent
+	case Opponent:
+		return AI
+	}
+	return Nobody
+}
+
+// Tally contains the information about a deal that is needed to
+// determine whether it is over, who won it and how many game points
+// the winner earns.
+//
+// TargetScore is the number of points needed to win (66 if it is zero).
+// Score, Tricks and ClosedBy are from the point of view of the AI.
+// TricksWhenClosed is the number of tricks the opponent of the player
+// that closed the game had won at the time of closing. DeclaredBy is the
+// player that declared having 66 points. IsPlayedOut tells if all the
+// tricks have been played and LastTrickWinner is the player that won
+// the last trick played.
+type Tally struct {
+	TargetScore      int
+	Score            int
+	OpponentScore    int
+	Tricks           int
+	OpponentTricks   int
+	ClosedBy         Player
+	TricksWhenClosed int
+	DeclaredBy       Player
+	IsPlayedOut      bool
+	LastTrickWinner  Player
+}
+
+// Result returns the winner of the deal and the game points they earn.
+// If the deal is not over yet the result is Nobody and 0 points.
+//
+// The deal is over when a player declares having 66 points (or the target
+// score) or when all the tricks are played. In the latter case the winner
+// of the last trick is considered to have declared.
+//
+// A player that declares with 66 points or more wins the deal. The winner
+// earns 3 game points if the loser has not taken a trick, 2 game points
+// if the loser has less than 33 points (half the target score) and 1 game
+// point otherwise.
+//
+// A player that declares with less than 66 points loses the deal and
+// the opponent earns 3 game points if they have not taken a trick and
+// 2 game points otherwise.
+//
+// If a player closes the game and the opponent wins or nobody wins by
+// declaring, the opponent of the closing player earns 3 game points if
+// they had not taken a trick at the time of closing and 2 game points
+// otherwise.
+//
+// If the game is not closed and all tricks are played without the winner
+// of the last trick collecting 66 points, they earn 1 game point.
+func (t Tally) Result() (Player, int) {
+	target := t.target()
+	switch {
+	case t.DeclaredBy != Nobody && t.score(t.DeclaredBy) >= target:
+		return t.win(t.DeclaredBy)
+	case t.DeclaredBy != Nobody:
+		return t.penalty(t.DeclaredBy, t.tricks(t.DeclaredBy.Other()))
+	case t.IsPlayedOut && t.score(t.LastTrickWinner) >= target:
+		return t.win(t.LastTrickWinner)
+	case t.IsPlayedOut && t.ClosedBy != Nobody:
+		return t.penalty(t.ClosedBy, t.TricksWhenClosed)
+	case t.IsPlayedOut:
+		return t.LastTrickWinner, 1
+	}
+	return Nobody, 0
+}
+
+func (t Tally) target() int {
+	if t.TargetScore == 0 {
+		return StandardRules.TargetScore
+	}
+	return t.TargetScore
+}
+
+func (t Tally) score(p Player) int {
+	if p == Opponent {
+		return t.OpponentScore
+	}
+	return t.Score
+}
+
+func (t Tally) tricks(p Player) int {
+	if p == Opponent {
+		return t.OpponentTricks
+	}
+	return t.Tricks
+}
+
+func (t Tally) win(winner Player) (Player, int) {
+	if t.ClosedBy == winner.Other() {
+		return t.penalty(t.ClosedBy, t.TricksWhenClosed)
+	}
+
+	loser := winner.Other()
+	switch {
+	case t.tricks(loser) == 0:
+		return winner, 3
+	case 2*t.score(loser) < t.target():
+		return winner, 2
+	}
+	return winner, 1
+}
+
+// penalty returns the result of a deal lost by the given player
+// because of a wrong declaration or because they closed the game
+// and failed to win. The opponent had won the given number of tricks
+// at the time.
+func (t Tally) penalty(loser Player, opponentTricks int) (Player, int) {
+	if opponentTricks == 0 {
+		return loser.Other(), 3
+	}
+	return loser.Other(), 2
+}
@@ -0,0 +1,45 @@
+package santase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTallyResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		tally  Tally
+		winner Player
+		points int
+	}{
+		{"not over", Tally{Score: 40, OpponentScore: 50, Tricks: 3, OpponentTricks: 4}, Nobody, 0},
+		{"not declared", Tally{Score: 70, OpponentScore: 20, Tricks: 5, OpponentTricks: 1}, Nobody, 0},
+		{"one game point", Tally{Score: 66, OpponentScore: 33, Tricks: 5, OpponentTricks: 3, DeclaredBy: AI}, AI, 1},
+		{"two game points", Tally{Score: 70, OpponentScore: 32, Tricks: 5, OpponentTricks: 3, DeclaredBy: AI}, AI, 2},
+		{"three game points", Tally{Score: 0, OpponentScore: 66, Tricks: 0, OpponentTricks: 6, DeclaredBy: Opponent}, Opponent, 3},
+		{"announcement without tricks", Tally{Score: 20, OpponentScore: 70, Tricks: 0, OpponentTricks: 5, DeclaredBy: Opponent}, Opponent, 3},
+		{"wrong declaration", Tally{Score: 60, OpponentScore: 40, Tricks: 4, OpponentTricks: 3, DeclaredBy: AI}, Opponent, 2},
+		{"wrong declaration against player without tricks", Tally{Score: 60, OpponentScore: 0, Tricks: 4, OpponentTricks: 0, DeclaredBy: AI}, Opponent, 3},
+		{"last trick", Tally{Score: 60, OpponentScore: 60, Tricks: 6, OpponentTricks: 6, IsPlayedOut: true, LastTrickWinner: Opponent}, Opponent, 1},
+		{"last trick with 66 points", Tally{Score: 30, OpponentScore: 90, Tricks: 3, OpponentTricks: 9, IsPlayedOut: true, LastTrickWinner: Opponent}, Opponent, 2},
+		{"closer wins", Tally{Score: 70, OpponentScore: 10, Tricks: 5, OpponentTricks: 1, ClosedBy: AI, DeclaredBy: AI}, AI, 2},
+		{"closer loses", Tally{Score: 40, OpponentScore: 66, Tricks: 3, OpponentTricks: 5, ClosedBy: AI, TricksWhenClosed: 1, DeclaredBy: Opponent}, Opponent, 2},
+		{"closer loses to player without tricks", Tally{Score: 66, OpponentScore: 50, Tricks: 3, OpponentTricks: 5, ClosedBy: Opponent, DeclaredBy: AI}, AI, 3},
+		{"closer plays out", Tally{Score: 60, OpponentScore: 50, Tricks: 5, OpponentTricks: 4, ClosedBy: AI, TricksWhenClosed: 2, IsPlayedOut: true, LastTrickWinner: AI}, Opponent, 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			winner, points := test.tally.Result()
+			assert.Equal(t, test.winner, winner)
+			assert.Equal(t, test.points, points)
+		})
+	}
+}
+
+func TestPlayerOther(t *testing.T) {
+	assert.Equal(t, Opponent, AI.Other())
+	assert.Equal(t, AI, Opponent.Other())
+	assert.Equal(t, Nobody, Nobody.Other())
+}
@@ -0,0 +1,174 @@
+package santase
+
+import (
+	"errors"
+	"fmt"
+)
+
+// GameStateVersion is the version of the GameState format produced
+// by Game.Snapshot. It is increased whenever the format changes in
+// a way older versions of the library cannot read.
+const GameStateVersion = 1
+
+// Errors returned by RestoreGame.
+var (
+	ErrUnsupportedVersion = errors.New("unsupported game state version")
+	ErrInvalidGameState   = errors.New("invalid game state")
+)
+
+// GameState is a snapshot of everything a Game knows, created by
+// Game.Snapshot and turned back into a Game by RestoreGame.
+//
+// Unlike Game all of its fields are exported, so it can be encoded
+// with encoding/json (cards use the notation of Card.MarshalText) or
+// encoding/gob and persisted. Version is the version of the format
+// (see GameStateVersion). The agent of the game is not part of the
+// snapshot.
+type GameState struct {
+	Version              int     `json:"version"`
+	Rules                Rules   `json:"rules"`
+	Trump                Suit    `json:"trump"`
+	TrumpCard            *Card   `json:"trumpCard"`
+	CardPlayed           *Card   `json:"cardPlayed"`
+	Hand                 Hand    `json:"hand"`
+	KnownOpponentCards   Hand    `json:"knownOpponentCards"`
+	SeenCards            Pile    `json:"seenCards"`
+	UnseenCards          Pile    `json:"unseenCards"`
+	IsOpponentMove       bool    `json:"isOpponentMove"`
+	IsClosed             bool    `json:"isClosed"`
+	Score                int     `json:"score"`
+	OpponentScore        int     `json:"opponentScore"`
+	PendingScore         int     `json:"pendingScore"`
+	OpponentPendingScore int     `json:"opponentPendingScore"`
+	Tricks               int     `json:"tricks"`
+	OpponentTricks       int     `json:"opponentTricks"`
+	ClosedBy             Player  `json:"closedBy"`
+	TricksWhenClosed     int     `json:"tricksWhenClosed"`
+	DeclaredBy           Player  `json:"declaredBy"`
+	History              []Event `json:"history"`
+	InitialHand          Hand    `json:"initialHand"`
+	InitialTrumpCard     Card    `json:"initialTrumpCard"`
+}
+
+// Snapshot returns the current state of the game. The result does not
+// share any data with the game, so the game can continue being played
+// after taking a snapshot.
+func (g *Game) Snapshot() GameState {
+	return GameState{
+		Version:              GameStateVersion,
+		Rules:                g.rules,
+		Trump:                g.trump,
+		TrumpCard:            g.GetTrumpCard(),
+		CardPlayed:           g.GetCardPlayed(),
+		Hand:                 g.hand.Clone(),
+		KnownOpponentCards:   g.knownOpponentCards.Clone(),
+		SeenCards:            g.seenCards.Clone(),
+		UnseenCards:          g.unseenCards.Clone(),
+		IsOpponentMove:       g.isOpponentMove,
+		IsClosed:             g.isClosed,
+		Score:                g.score,
+		OpponentScore:        g.opponentScore,
+		PendingScore:         g.pendingScore,
+		OpponentPendingScore: g.opponentPending,
+		Tricks:               g.tricks,
+		OpponentTricks:       g.opponentTricks,
+		ClosedBy:             g.closedBy,
+		TricksWhenClosed:     g.tricksWhenClosed,
+		DeclaredBy:           g.declaredBy,
+		History:              g.History(),
+		InitialHand:          g.initialHand.Clone(),
+		InitialTrumpCard:     g.initialTrumpCard,
+	}
+}
+
+// RestoreGame creates a Game from a snapshot taken with Game.Snapshot.
+// The restored game continues exactly where the snapshot was taken.
+// The agent is not restored, so SetAgent should be called before
+// GetMove. The restored game cannot undo the actions that happened
+// before the snapshot was taken (see Game.Undo).
+//
+// An error is returned if the snapshot has an unsupported version or
+// if its cards are not a valid split of the deck.
+func RestoreGame(state GameState) (Game, error) {
+	if state.Version != GameStateVersion {
+		return Game{}, fmt.Errorf("%w: %d", ErrUnsupportedVersion, state.Version)
+	}
+
+	if err := state.validate(); err != nil {
+		return Game{}, err
+	}
+
+	return state.game(), nil
+}
+
+// game creates a Game in the saved state without validating it.
+func (s *GameState) game() Game {
+	game := Game{
+		trump:              s.Trump,
+		score:              s.Score,
+		opponentScore:      s.OpponentScore,
+		pendingScore:       s.PendingScore,
+		opponentPending:    s.OpponentPendingScore,
+		hand:               s.Hand.Clone(),
+		knownOpponentCards: s.KnownOpponentCards.Clone(),
+		seenCards:          s.SeenCards.Clone(),
+		unseenCards:        s.UnseenCards.Clone(),
+		isOpponentMove:     s.IsOpponentMove,
+		isClosed:           s.IsClosed,
+		tricks:             s.Tricks,
+		opponentTricks:     s.OpponentTricks,
+		closedBy:           s.ClosedBy,
+		tricksWhenClosed:   s.TricksWhenClosed,
+		declaredBy:         s.DeclaredBy,
+		rules:              s.Rules,
+		history:            append([]Event(nil), s.History...),
+		initialHand:        s.InitialHand.Clone(),
+		initialTrumpCard:   s.InitialTrumpCard,
+		agent:              dummyAgent{},
+	}
+
+	if s.TrumpCard != nil {
+		trumpCard := *s.TrumpCard
+		game.trumpCard = &trumpCard
+	}
+
+	if s.CardPlayed != nil {
+		cardPlayed := *s.CardPlayed
+		game.cardPlayed = &cardPlayed
+	}
+
+	return game
+}
+
+// validate checks that every card of the deck is in exactly
+// one place (a hand, a pile, the trump card or on the table).
+func (s *GameState) validate() error {
+	if s.Hand.Len() > 6 || s.KnownOpponentCards.Len() > 6 {
+		return fmt.Errorf("%w: too many cards in hand", ErrInvalidGameState)
+	}
+
+	if s.TrumpCard != nil && s.TrumpCard.Suit != s.Trump {
+		return fmt.Errorf("%w: trump card is not of the trump suit", ErrInvalidGameState)
+	}
+
+	sets := []CardSet{s.Hand.cards, s.KnownOpponentCards.cards, s.SeenCards.cards, s.UnseenCards.cards}
+	for _, card := range []*Card{s.TrumpCard, s.CardPlayed} {
+		if card != nil {
+			sets = append(sets, NewCardSet(*card))
+		}
+	}
+
+	var cards CardSet
+	for _, set := range sets {
+		if duplicates := cards.Intersect(set); !duplicates.IsEmpty() {
+			return fmt.Errorf("%w: duplicate card %s", ErrInvalidGameState, duplicates.Cards()[0])
+		}
+		cards = cards.Union(set)
+	}
+
+	if cards != FullDeck {
+		return fmt.Errorf("%w: expected %d cards, got %d", ErrInvalidGameState, FullDeck.Len(), cards.Len())
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package santase
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// snapshotAgent saves and restores the game every time
+// it is asked for a move and checks nothing is lost.
+type snapshotAgent struct {
+	t *testing.T
+}
+
+func (a snapshotAgent) GetMove(g *Game) Move {
+	data, err := json.Marshal(g.Snapshot())
+	assert.Nil(a.t, err)
+
+	var state GameState
+	assert.Nil(a.t, json.Unmarshal(data, &state))
+
+	restored, err := RestoreGame(state)
+	assert.Nil(a.t, err)
+	restored.SetAgent(a)
+	// the actions that can be undone are not part of the snapshot
+	restored.undo = g.undo
+	assert.Equal(a.t, *g, restored)
+
+	restored.SetAgent(lowestCardAgent{})
+	return restored.GetMove()
+}
+
+func TestSnapshot(t *testing.T) {
+	table := NewTable(snapshotAgent{t}, snapshotAgent{t})
+	rules := StandardRules
+	rules.AnnounceOnFirstTrick = true
+	table.SetRules(rules)
+
+	for i := 0; i < 10; i++ {
+		_, err := table.Play()
+		assert.Nil(t, err)
+	}
+}
+
+func TestSnapshotIsIndependent(t *testing.T) {
+	game := createSampleGame()
+	state := game.Snapshot()
+
+	game.UpdateOpponentMove(Move{Card: NewCard(Jack, Spades)})
+	assert.Nil(t, state.CardPlayed)
+	assert.True(t, state.UnseenCards.HasCard(NewCard(Jack, Spades)))
+
+	restored, err := RestoreGame(state)
+	assert.Nil(t, err)
+	restored.UpdateOpponentMove(Move{Card: NewCard(Ace, Hearts)})
+	assert.Equal(t, NewCard(Jack, Spades), *game.GetCardPlayed())
+	assert.Equal(t, NewCard(Ace, Hearts), *restored.GetCardPlayed())
+}
+
+func TestRestoreGameInvalidState(t *testing.T) {
+	game := createSampleGame()
+
+	state := game.Snapshot()
+	state.Version = GameStateVersion + 1
+	_, err := RestoreGame(state)
+	assert.True(t, errors.Is(err, ErrUnsupportedVersion))
+
+	state = game.Snapshot()
+	state.SeenCards.AddCard(NewCard(Nine, Diamonds))
+	_, err = RestoreGame(state)
+	assert.True(t, errors.Is(err, ErrInvalidGameState))
+
+	state = game.Snapshot()
+	state.UnseenCards.RemoveCard(NewCard(Ace, Hearts))
+	_, err = RestoreGame(state)
+	assert.True(t, errors.Is(err, ErrInvalidGameState))
+
+	var decoded GameState
+	err = json.Unmarshal([]byte(`{"version":1,"hand":["9D","XX"]}`), &decoded)
+	assert.True(t, errors.Is(err, ErrInvalidCard))
+}
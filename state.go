@@ -0,0 +1,358 @@
+package santase
+
+import "errors"
+
+// ErrUnseenCards is returned by Game.State when the passed cards
+// are not the unseen cards of the game.
+var ErrUnseenCards = errors.New("cards are not the unseen cards of the game")
+
+// State is the complete state of a deal, including what is hidden from
+// the players: the hands of both players and the order of the cards in
+// the stack. Players are named from the point of view of the AI like in
+// Game.
+//
+// Unlike a Game, which knows only what the AI can see and is told about
+// the moves of the opponent and the cards drawn, a State plays the whole
+// deal by itself: after a trick is played both players draw their cards
+// from the stack. This makes it suitable for simulations, like the ones
+// of the agents searching for a move. Moves are checked against the same
+// rules in both, so a move the AI can play in a Game can be applied to a
+// State of the game (see Game.State) and the other way around.
+//
+// A State is a value: a copy of it can be played independently of the
+// original.
+type State struct {
+	rules           Rules
+	trump           Suit
+	hand            Hand
+	opponentHand    Hand
+	pendingScore    int
+	opponentPending int
+	stack           []Card
+	trumpCard       *Card
+	cardPlayed      *Card
+	isOpponentMove  bool
+	isClosed        bool
+	tally           Tally
+}
+
+// NewState creates the state of a new deal with the given hands of the
+// AI and the opponent, the trump card and the rest of the cards in the
+// stack in the order they are drawn (the trump card is drawn last).
+// isOpponentMove tells if the opponent plays first.
+//
+// Panics if a hand does not have 6 cards or if the cards are not
+// all the cards of the deck exactly once.
+func NewState(hand Hand, opponentHand Hand, trumpCard Card, stack []Card, isOpponentMove bool, rules Rules) State {
+	if hand.Len() != 6 || opponentHand.Len() != 6 {
+		panic("player's hand is not complete")
+	}
+
+	deck := append(hand.ToSlice(), opponentHand.ToSlice()...)
+	deck = append(deck, trumpCard)
+	checkDeck(append(deck, stack...))
+
+	return State{
+		rules:          rules,
+		trump:          trumpCard.Suit,
+		hand:           hand,
+		opponentHand:   opponentHand,
+		stack:          append([]Card(nil), stack...),
+		trumpCard:      &trumpCard,
+		isOpponentMove: isOpponentMove,
+		tally:          Tally{TargetScore: rules.TargetScore},
+	}
+}
+
+// State returns a complete state of the game, in which the unseen cards
+// (see GetUnseenCards) are dealt in the given order: the first ones to
+// the opponent, until their hand is complete together with the cards
+// known to be in it, and the rest to the stack in the order they are
+// drawn. Shuffling the unseen cards gives a random deal compatible with
+// what the AI knows about the game.
+//
+// ErrUnseenCards is returned if the cards are not the unseen cards of
+// the game. ErrGameOver and ErrDrawPending are returned if the game is
+// over or the AI has not drawn its card yet.
+func (g *Game) State(unseen []Card) (State, error) {
+	if g.IsOver() {
+		return State{}, ErrGameOver
+	}
+
+	if g.isDrawPending() {
+		return State{}, ErrDrawPending
+	}
+
+	var cards CardSet
+	for _, card := range unseen {
+		cards.Add(card)
+	}
+	if len(unseen) != g.unseenCards.Len() || cards != g.unseenCards.cards {
+		return State{}, ErrUnseenCards
+	}
+
+	dealt := g.opponentHandSize() - g.knownOpponentCards.Len()
+	if dealt < 0 || dealt > len(unseen) {
+		return State{}, ErrUnseenCards
+	}
+
+	opponentHand := g.knownOpponentCards
+	for _, card := range unseen[:dealt] {
+		opponentHand.AddCard(card)
+	}
+
+	return State{
+		rules:           g.rules,
+		trump:           g.trump,
+		hand:            g.hand,
+		opponentHand:    opponentHand,
+		pendingScore:    g.pendingScore,
+		opponentPending: g.opponentPending,
+		stack:           append([]Card(nil), unseen[dealt:]...),
+		trumpCard:       g.GetTrumpCard(),
+		cardPlayed:      g.GetCardPlayed(),
+		isOpponentMove:  g.isOpponentMove,
+		isClosed:        g.isClosed,
+		tally:           g.GetTally(),
+	}, nil
+}
+
+// GetHand returns the hand of the AI player.
+func (s *State) GetHand() Hand {
+	return s.hand
+}
+
+// GetOpponentHand returns the hand of the opponent.
+func (s *State) GetOpponentHand() Hand {
+	return s.opponentHand
+}
+
+// GetStack returns the cards in the stack in the order they are
+// drawn, without the trump card.
+func (s *State) GetStack() []Card {
+	return append([]Card(nil), s.stack...)
+}
+
+// GetTrump returns the trump suit of the deal.
+func (s *State) GetTrump() Suit {
+	return s.trump
+}
+
+// GetTrumpCard returns a pointer to the trump card placed on the table.
+// If all cards have been drawn the result will be nil.
+func (s *State) GetTrumpCard() *Card {
+	if s.trumpCard == nil {
+		return nil
+	}
+	card := *s.trumpCard
+	return &card
+}
+
+// GetCardPlayed returns a pointer to the card placed on the
+// table by one of the players. If there is no card played
+// the result will be nil.
+func (s *State) GetCardPlayed() *Card {
+	if s.cardPlayed == nil {
+		return nil
+	}
+	card := *s.cardPlayed
+	return &card
+}
+
+// GetRules returns the rules the deal is played with.
+func (s *State) GetRules() Rules {
+	return s.rules
+}
+
+// GetTally returns the information about the deal that is needed
+// to determine its result.
+func (s *State) GetTally() Tally {
+	return s.tally
+}
+
+// IsOpponentMove returns if it is turn for the opponent
+// (from the point of view of the AI) to play next.
+func (s *State) IsOpponentMove() bool {
+	return s.isOpponentMove
+}
+
+// IsClosed returns if the game has been explicitly closed
+// by one of the players.
+func (s *State) IsClosed() bool {
+	return s.isClosed
+}
+
+// IsOver returns if the deal has ended. After that no more
+// moves can be played.
+//
+// See Tally.Result for the rules that determine when a deal ends.
+func (s *State) IsOver() bool {
+	winner, _ := s.tally.Result()
+	return winner != Nobody
+}
+
+// LegalMoves returns every move the player to move can play, in the
+// same order as Game.LegalMoves. No moves are returned if the deal
+// is over.
+func (s *State) LegalMoves() []Move {
+	if s.IsOver() {
+		return nil
+	}
+	p := s.position()
+	return legalMoves(p.cards(), p.validateMove)
+}
+
+// Validate checks if the player to move can play the move. It returns
+// nil if the move is legal and the error of the rule the move violates
+// otherwise, just like Game.TryGetMove would for the same move of the
+// AI.
+func (s *State) Validate(move Move) error {
+	if s.IsOver() {
+		return ErrGameOver
+	}
+	return s.position().validateMove(move)
+}
+
+// Apply plays the move for the player to move. When the move completes
+// a trick, the winner of the trick and then the other player draw a card
+// from the stack, unless the game is closed or all cards have been drawn.
+//
+// If the move is not legal (see Validate) an error is returned and the
+// state is left unchanged.
+func (s *State) Apply(move Move) error {
+	if err := s.Validate(move); err != nil {
+		return err
+	}
+
+	s.apply(move)
+	return nil
+}
+
+func (s *State) position() position {
+	return position{
+		rules:      s.rules,
+		trump:      s.trump,
+		trumpCard:  s.trumpCard,
+		cardPlayed: s.cardPlayed,
+		isClosed:   s.isClosed,
+		tricks:     s.tally.Tricks + s.tally.OpponentTricks,
+		hand:       *s.handOf(s.player()),
+	}
+}
+
+// player returns the player to move.
+func (s *State) player() Player {
+	if s.isOpponentMove {
+		return Opponent
+	}
+	return AI
+}
+
+func (s *State) handOf(player Player) *Hand {
+	if player == Opponent {
+		return &s.opponentHand
+	}
+	return &s.hand
+}
+
+func (s *State) apply(move Move) {
+	player := s.player()
+	hand := s.handOf(player)
+
+	if move.Declare {
+		if move.IsAnnouncement {
+			s.announce(player, move.Card)
+		}
+		s.tally.DeclaredBy = player
+		return
+	}
+
+	if move.SwitchTrumpCard {
+		nineTrump := NewCard(Nine, s.trump)
+		hand.RemoveCard(nineTrump)
+		hand.AddCard(*s.trumpCard)
+		s.trumpCard = &nineTrump
+	}
+
+	if move.CloseGame {
+		s.isClosed = true
+		s.tally.ClosedBy = player
+		s.tally.TricksWhenClosed = s.tally.tricks(player.Other())
+	}
+
+	if move.IsAnnouncement {
+		s.announce(player, move.Card)
+	}
+
+	hand.RemoveCard(move.Card)
+
+	if s.cardPlayed == nil {
+		card := move.Card
+		s.cardPlayed = &card
+		s.isOpponentMove = !s.isOpponentMove
+	} else {
+		s.completeTrick(move.Card)
+	}
+}
+
+// announce adds the points of the announcement of the marriage the
+// passed card is part of to the score of the announcing player, or to
+// their pending points like in Game.
+func (s *State) announce(player Player, card Card) {
+	points := s.rules.AnnouncementPoints(card, s.trump)
+
+	switch {
+	case player == AI && s.rules.DeferAnnouncements && s.tally.Tricks == 0:
+		s.pendingScore += points
+	case player == AI:
+		s.tally.Score += points
+	case s.rules.DeferAnnouncements && s.tally.OpponentTricks == 0:
+		s.opponentPending += points
+	default:
+		s.tally.OpponentScore += points
+	}
+}
+
+// completeTrick finishes the trick with the response to the card
+// played, gives it to the player that played the stronger card and
+// lets the players draw.
+func (s *State) completeTrick(response Card) {
+	leader := s.player().Other()
+	winner := leader
+	if StrongerCard(s.cardPlayed, &response, s.trump) == &response {
+		winner = leader.Other()
+	}
+
+	s.tally.IsPlayedOut = s.hand.Len() == 0 && s.opponentHand.Len() == 0
+	points := Points(s.cardPlayed) + Points(&response)
+	if s.tally.IsPlayedOut && !s.isClosed {
+		points += s.rules.LastTrickBonus
+	}
+
+	if winner == AI {
+		s.tally.Score += points + s.pendingScore
+		s.pendingScore = 0
+		s.tally.Tricks++
+	} else {
+		s.tally.OpponentScore += points + s.opponentPending
+		s.opponentPending = 0
+		s.tally.OpponentTricks++
+	}
+	s.tally.LastTrickWinner = winner
+	s.isOpponentMove = winner == Opponent
+	s.cardPlayed = nil
+
+	if s.isClosed || s.trumpCard == nil {
+		return
+	}
+
+	s.handOf(winner).AddCard(s.stack[0])
+	if len(s.stack) > 1 {
+		s.handOf(winner.Other()).AddCard(s.stack[1])
+		s.stack = s.stack[2:]
+	} else {
+		s.handOf(winner.Other()).AddCard(*s.trumpCard)
+		s.stack = nil
+		s.trumpCard = nil
+	}
+}
@@ -0,0 +1,189 @@
+package santase
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createSampleState() State {
+	return NewState(
+		createSampleHand(),
+		NewHand(
+			NewCard(Jack, Spades),
+			NewCard(Queen, Spades),
+			NewCard(Ace, Hearts),
+			NewCard(Jack, Hearts),
+			NewCard(Nine, Clubs),
+			NewCard(Ace, Diamonds),
+		),
+		NewCard(Ten, Clubs),
+		[]Card{
+			NewCard(King, Diamonds),
+			NewCard(Jack, Diamonds),
+			NewCard(Ten, Diamonds),
+			NewCard(Nine, Hearts),
+			NewCard(Queen, Hearts),
+			NewCard(King, Hearts),
+			NewCard(Ten, Spades),
+			NewCard(Jack, Clubs),
+			NewCard(Queen, Clubs),
+			NewCard(King, Clubs),
+			NewCard(Ace, Clubs),
+		},
+		true,
+		StandardRules,
+	)
+}
+
+func TestNewStateInvalidDeck(t *testing.T) {
+	assert.PanicsWithValue(
+		t, "deck has invalid or duplicate cards",
+		func() {
+			NewState(createSampleHand(), createSampleHand(), NewCard(Ten, Clubs), AllCards[:11], false, StandardRules)
+		},
+	)
+}
+
+func TestStateApply(t *testing.T) {
+	state := createSampleState()
+
+	assert.Nil(t, state.Apply(Move{Card: NewCard(Jack, Spades)}))
+	assert.False(t, state.IsOpponentMove())
+	assert.Equal(t, NewCard(Jack, Spades), *state.GetCardPlayed())
+
+	assert.Nil(t, state.Apply(Move{Card: NewCard(Ace, Spades)}))
+	assert.Nil(t, state.GetCardPlayed())
+	assert.False(t, state.IsOpponentMove())
+	assert.Equal(t, 13, state.GetTally().Score)
+	assert.Equal(t, 1, state.GetTally().Tricks)
+
+	// the winner of the trick draws first
+	hand, opponentHand := state.GetHand(), state.GetOpponentHand()
+	assert.True(t, hand.HasCard(NewCard(King, Diamonds)))
+	assert.True(t, opponentHand.HasCard(NewCard(Jack, Diamonds)))
+	assert.Equal(t, 9, len(state.GetStack()))
+}
+
+func TestStateApplyIllegalMove(t *testing.T) {
+	state := createSampleState()
+	state.Apply(Move{Card: NewCard(Jack, Spades)})
+	state.Apply(Move{Card: NewCard(Ace, Spades)})
+	copied := state
+
+	err := state.Apply(Move{Card: NewCard(King, Diamonds), SwitchTrumpCard: true})
+	assert.Equal(t, ErrSwitchWithoutNine, err)
+	assert.Equal(t, copied, state)
+}
+
+func TestStateCopiesAreIndependent(t *testing.T) {
+	state := createSampleState()
+	copied := state
+
+	copied.Apply(Move{Card: NewCard(Jack, Spades)})
+	copied.Apply(Move{Card: NewCard(Ace, Spades)})
+
+	assert.Equal(t, createSampleState(), state)
+	assert.NotEqual(t, state, copied)
+}
+
+func TestStateSwitchAndAnnounce(t *testing.T) {
+	state := createSampleState()
+	state.Apply(Move{Card: NewCard(Jack, Spades)})
+	state.Apply(Move{Card: NewCard(Ace, Spades)})
+
+	// the AI drew the king of diamonds and leads
+	move := Move{Card: NewCard(Queen, Diamonds), IsAnnouncement: true, CloseGame: true}
+	assert.Nil(t, state.Apply(move))
+	assert.True(t, state.IsClosed())
+	assert.Equal(t, AI, state.GetTally().ClosedBy)
+	assert.Equal(t, 13+20, state.GetTally().Score)
+
+	// the opponent has to follow suit with a higher card
+	err := state.Apply(Move{Card: NewCard(Jack, Diamonds)})
+	assert.IsType(t, &IllegalResponseError{}, err)
+	assert.Equal(t, []Move{{Card: NewCard(Ace, Diamonds)}}, state.LegalMoves())
+}
+
+func TestStateDeclare(t *testing.T) {
+	state := createSampleState()
+	state.Apply(Move{Card: NewCard(Jack, Spades)})
+	state.Apply(Move{Card: NewCard(Ace, Spades)})
+
+	assert.Nil(t, state.Apply(Move{Declare: true}))
+	assert.True(t, state.IsOver())
+	assert.Nil(t, state.LegalMoves())
+	assert.Equal(t, ErrGameOver, state.Validate(Move{Card: NewCard(King, Diamonds)}))
+
+	// the AI declared with 13 points and the opponent has not taken a trick
+	winner, points := state.GetTally().Result()
+	assert.Equal(t, Opponent, winner)
+	assert.Equal(t, 3, points)
+}
+
+func TestGameState(t *testing.T) {
+	game := createSampleGame()
+	game.UpdateOpponentMove(Move{Card: NewCard(Jack, Spades)})
+
+	unseenCards := game.GetUnseenCards()
+	unseen := unseenCards.ToSlice()
+	state, err := game.State(unseen)
+	assert.Nil(t, err)
+
+	assert.Equal(t, game.GetHand(), state.GetHand())
+	assert.Equal(t, NewHand(unseen[:5]...), state.GetOpponentHand())
+	assert.Equal(t, unseen[5:], state.GetStack())
+	assert.Equal(t, NewCard(Jack, Spades), *state.GetCardPlayed())
+	assert.Equal(t, game.LegalMoves(), state.LegalMoves())
+
+	_, err = game.State(unseen[1:])
+	assert.Equal(t, ErrUnseenCards, err)
+
+	_, err = game.State(append(unseen[1:], NewCard(Nine, Diamonds)))
+	assert.Equal(t, ErrUnseenCards, err)
+
+	game.SetAgent(fixedAgent{Move{Card: NewCard(Ace, Spades)}})
+	game.GetMove()
+	unseenCards = game.GetUnseenCards()
+	_, err = game.State(unseenCards.ToSlice())
+	assert.Equal(t, ErrDrawPending, err)
+}
+
+// TestStatePlaysLikeTable plays the same deals at a Table and with a
+// State, choosing the same moves, and checks that they end the same.
+func TestStatePlaysLikeTable(t *testing.T) {
+	rules := StandardRules
+	rules.AnnounceOnFirstTrick = true
+	rules.SwitchWhenResponding = true
+
+	for _, rules := range []Rules{StandardRules, rules} {
+		for seed := int64(0); seed < 50; seed++ {
+			deck := shuffledDeck(rand.New(rand.NewSource(seed)))
+
+			agent := legalMovesAgent{rand.New(rand.NewSource(seed))}
+			table := NewTable(agent, agent)
+			table.SetRules(rules)
+			result, err := table.PlayDeck(deck)
+			assert.Nil(t, err)
+
+			rng := rand.New(rand.NewSource(seed))
+			state := NewState(NewHand(deck[:6]...), NewHand(deck[6:12]...), deck[12], deck[13:], false, rules)
+			for !state.IsOver() {
+				var moves []Move
+				for _, move := range state.LegalMoves() {
+					if !move.Declare {
+						moves = append(moves, move)
+					}
+				}
+				assert.Nil(t, state.Apply(moves[rng.Intn(len(moves))]))
+			}
+
+			tally := state.GetTally()
+			winner, points := tally.Result()
+			assert.Equal(t, result.Scores, [2]int{tally.Score, tally.OpponentScore})
+			assert.Equal(t, result.Winner == 0, winner == AI)
+			assert.Equal(t, result.GamePoints, points)
+		}
+	}
+}
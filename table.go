@@ -0,0 +1,276 @@
+package santase
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Trick is a record of one trick played at a Table.
+//
+// Seats are identified by their index: 0 for the agent that leads
+// the first trick and 1 for the other agent. Points include the last
+// trick bonus if the trick earned it.
+type Trick struct {
+	Leader   int
+	Lead     Move
+	Response Move
+	Winner   int
+	Points   int
+}
+
+// DealResult contains the outcome of a deal played at a Table.
+//
+// Scores holds the points collected by each seat and Tricks holds
+// the tricks in the order they were played. Winner is the seat that
+// won the deal and GamePoints are the game points it earned. Record
+// is the deal from the point of view of the first seat (including the
+// hand dealt to the second seat), which can be written with a Writer.
+type DealResult struct {
+	Scores     [2]int
+	Tricks     []Trick
+	Winner     int
+	GamePoints int
+	Record     Record
+}
+
+// Table is a referee that deals the cards and plays a deal of santase
+// between two agents. Each seat is given its own Game, so an agent sees
+// only what a player sitting at the table would see.
+type Table struct {
+	agents [2]Agent
+	rules  Rules
+	rng    *rand.Rand
+}
+
+// NewTable creates a new Table for the two agents. The first agent
+// leads the first trick.
+func NewTable(first Agent, second Agent) *Table {
+	return &Table{
+		agents: [2]Agent{first, second},
+		rules:  StandardRules,
+	}
+}
+
+// SetRules sets the rules the following deals at the table are
+// played with. By default the StandardRules are used.
+func (t *Table) SetRules(rules Rules) {
+	t.rules = rules
+}
+
+// SetRand sets the source of randomness used to shuffle the cards.
+// By default the global source of math/rand is used. With a seeded
+// source and agents that are seeded as well the deals at the table
+// can be replayed exactly.
+func (t *Table) SetRand(rng *rand.Rand) {
+	t.rng = rng
+}
+
+// Play shuffles the cards and plays a complete deal.
+//
+// If one of the agents chooses an invalid move an error is returned.
+func (t *Table) Play() (DealResult, error) {
+	return t.PlayDeck(shuffledDeck(t.rng))
+}
+
+// PlayDeck plays a complete deal with the cards in the given order.
+//
+// The first six cards are dealt to the first agent and the next six
+// to the second agent. The thirteenth card is the trump card and the
+// rest of the cards form the stack. Cards are drawn from the stack in
+// order and the trump card is drawn last.
+//
+// If the deck does not contain every card exactly once a panic will occur.
+// If one of the agents chooses an invalid move an error is returned.
+func (t *Table) PlayDeck(deck []Card) (DealResult, error) {
+	checkDeck(deck)
+
+	var games [2]*Game
+	for seat := range games {
+		game := CreateGameWithRules(NewHand(deck[seat*6:seat*6+6]...), deck[12], seat != 0, t.rules)
+		games[seat] = &game
+	}
+
+	return t.play(games, deck[12], deck[13:])
+}
+
+// PlayMatch plays deals with shuffled cards until one of the agents
+// collects 11 game points. The first agent leads the first deal and
+// the leaders of the next deals are chosen by the dealer rule. The
+// leader of a deal is dealt the first six cards of the deck.
+//
+// The returned Match is from the point of view of the first agent
+// and the results of the deals are in the order they were played.
+// If one of the agents chooses an invalid move an error is returned.
+func (t *Table) PlayMatch(dealerRule DealerRule) (*Match, []DealResult, error) {
+	match := NewMatch(dealerRule, false)
+	match.SetRules(t.rules)
+
+	var results []DealResult
+	for !match.IsOver() {
+		deck := shuffledDeck(t.rng)
+		first, second := deck[0:6], deck[6:12]
+		if match.IsOpponentFirst() {
+			first, second = second, first
+		}
+
+		var games [2]*Game
+		var err error
+		games[0], err = match.NewDeal(NewHand(first...), deck[12])
+		if err != nil {
+			return match, results, err
+		}
+
+		other := CreateGameWithRules(NewHand(second...), deck[12], !games[0].IsOpponentMove(), t.rules)
+		games[1] = &other
+
+		result, err := t.play(games, deck[12], deck[13:])
+		results = append(results, result)
+		if err != nil {
+			return match, results, err
+		}
+	}
+
+	return match, results, nil
+}
+
+func (t *Table) play(games [2]*Game, trumpCard Card, stack []Card) (DealResult, error) {
+	for seat, game := range games {
+		game.SetAgent(t.agents[seat])
+	}
+
+	var result DealResult
+	isClosed := false
+	isTrumpCardTaken := false
+	for !games[0].IsOver() {
+		leader := 0
+		if games[0].IsOpponentMove() {
+			leader = 1
+		}
+
+		lead, err := play(games, leader)
+		if err != nil {
+			return result, err
+		}
+
+		if lead.SwitchTrumpCard {
+			trumpCard = NewCard(Nine, trumpCard.Suit)
+		}
+
+		if lead.CloseGame {
+			isClosed = true
+		}
+
+		if games[0].IsOver() {
+			// the leader declared instead of playing a card
+			break
+		}
+
+		response, err := play(games, 1-leader)
+		if err != nil {
+			return result, err
+		}
+
+		if response.SwitchTrumpCard {
+			trumpCard = NewCard(Nine, trumpCard.Suit)
+		}
+
+		winner := leader
+		if games[leader].IsOpponentMove() {
+			winner = 1 - leader
+		}
+
+		points := Points(&lead.Card) + Points(&response.Card)
+		if !isClosed && games[winner].hand.Len() == 0 {
+			points += t.rules.LastTrickBonus
+		}
+
+		result.Tricks = append(result.Tricks, Trick{
+			Leader:   leader,
+			Lead:     lead,
+			Response: response,
+			Winner:   winner,
+			Points:   points,
+		})
+
+		if isClosed || isTrumpCardTaken {
+			continue
+		}
+
+		var drawn [2]Card
+		drawn[winner] = stack[0]
+		if len(stack) > 1 {
+			drawn[1-winner] = stack[1]
+			stack = stack[2:]
+		} else {
+			drawn[1-winner] = trumpCard
+			stack = nil
+			isTrumpCardTaken = true
+		}
+
+		for _, seat := range []int{winner, 1 - winner} {
+			if err := games[seat].TryUpdateDrawnCard(drawn[seat]); err != nil {
+				return result, fmt.Errorf("seat %d: %w", seat, err)
+			}
+		}
+	}
+
+	result.Scores[0] = games[0].GetScore()
+	result.Scores[1] = games[1].GetScore()
+	if games[0].Winner() == Opponent {
+		result.Winner = 1
+	}
+	result.GamePoints = games[0].GamePoints()
+	result.Record = NewRecord(games[0])
+	opponentHand := games[1].initialHand.Clone()
+	result.Record.OpponentHand = &opponentHand
+	return result, nil
+}
+
+// play asks the agent in the given seat for its move and
+// informs the other seat about it.
+func play(games [2]*Game, seat int) (Move, error) {
+	move, err := games[seat].TryGetMove()
+	if err != nil {
+		return move, fmt.Errorf("seat %d: %w", seat, err)
+	}
+
+	if err := games[1-seat].TryUpdateOpponentMove(move); err != nil {
+		return move, fmt.Errorf("seat %d: %w", seat, err)
+	}
+
+	return move, nil
+}
+
+// shuffledDeck returns the cards in random order using rng,
+// or using the global source of math/rand if rng is nil.
+func shuffledDeck(rng *rand.Rand) []Card {
+	deck := make([]Card, len(AllCards))
+	copy(deck, AllCards)
+	swap := func(i, j int) {
+		deck[i], deck[j] = deck[j], deck[i]
+	}
+	if rng == nil {
+		rand.Shuffle(len(deck), swap)
+	} else {
+		rng.Shuffle(len(deck), swap)
+	}
+	return deck
+}
+
+func checkDeck(deck []Card) {
+	if len(deck) != len(AllCards) {
+		panic("deck does not have 24 cards")
+	}
+
+	remaining := NewPile()
+	for _, card := range AllCards {
+		remaining.AddCard(card)
+	}
+
+	for _, card := range deck {
+		if !remaining.HasCard(card) {
+			panic("deck has invalid or duplicate cards")
+		}
+		remaining.RemoveCard(card)
+	}
+}
@@ -0,0 +1,154 @@
+package santase
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lowestCardAgent always plays the lowest valid card in its hand
+// and declares as soon as it has collected the target score.
+type lowestCardAgent struct{}
+
+func (a lowestCardAgent) GetMove(g *Game) Move {
+	hand := g.GetHand()
+	cardPlayed := g.GetCardPlayed()
+	if cardPlayed == nil && g.GetScore() >= g.GetRules().TargetScore {
+		return Move{Declare: true}
+	}
+
+	if cardPlayed != nil && (g.IsClosed() || g.GetTrumpCard() == nil) {
+		hand = hand.GetValidResponses(*cardPlayed, g.GetTrump())
+	}
+
+	cards := hand.ToSlice()
+	sort.Slice(cards, func(i, j int) bool {
+		return cards[i].Rank < cards[j].Rank || (cards[i].Rank == cards[j].Rank && cards[i].Suit < cards[j].Suit)
+	})
+	return Move{Card: cards[0]}
+}
+
+func TestTablePlayDeck(t *testing.T) {
+	table := NewTable(lowestCardAgent{}, lowestCardAgent{})
+	result, err := table.PlayDeck(AllCards)
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, result.Tricks)
+	assert.Equal(t, 0, result.Tricks[0].Leader)
+
+	var scores [2]int
+	for i, trick := range result.Tricks {
+		scores[trick.Winner] += trick.Points
+		if i > 0 {
+			assert.Equal(t, result.Tricks[i-1].Winner, trick.Leader)
+		}
+	}
+	assert.Equal(t, scores, result.Scores)
+}
+
+func TestTablePlay(t *testing.T) {
+	table := NewTable(lowestCardAgent{}, lowestCardAgent{})
+	for i := 0; i < 20; i++ {
+		_, err := table.Play()
+		assert.Nil(t, err)
+	}
+}
+
+func TestTableInvalidDeck(t *testing.T) {
+	table := NewTable(lowestCardAgent{}, lowestCardAgent{})
+
+	assert.PanicsWithValue(
+		t, "deck does not have 24 cards",
+		func() { table.PlayDeck(AllCards[1:]) },
+	)
+
+	deck := make([]Card, len(AllCards))
+	copy(deck, AllCards)
+	deck[0] = deck[1]
+	assert.PanicsWithValue(
+		t, "deck has invalid or duplicate cards",
+		func() { table.PlayDeck(deck) },
+	)
+}
+
+func TestTableInvalidMove(t *testing.T) {
+	table := NewTable(fixedAgent{Move{Card: NewCard(Ace, Spades)}}, lowestCardAgent{})
+	_, err := table.PlayDeck(AllCards)
+
+	assert.True(t, errors.Is(err, ErrCardNotInHand))
+}
+
+func TestTableResult(t *testing.T) {
+	table := NewTable(lowestCardAgent{}, lowestCardAgent{})
+	for i := 0; i < 20; i++ {
+		result, err := table.Play()
+		assert.Nil(t, err)
+
+		winner := result.Winner
+		lastTrick := result.Tricks[len(result.Tricks)-1]
+		if result.Scores[winner] < 66 {
+			// played out without reaching 66 points
+			assert.Equal(t, winner, lastTrick.Winner)
+			assert.Equal(t, 1, result.GamePoints)
+		} else {
+			// the winner declared after winning the last trick
+			assert.Equal(t, winner, lastTrick.Winner)
+			assert.True(t, result.GamePoints >= 1 && result.GamePoints <= 3)
+		}
+	}
+}
+
+func TestTablePlayMatch(t *testing.T) {
+	table := NewTable(lowestCardAgent{}, lowestCardAgent{})
+	match, results, err := table.PlayMatch(DealerRotation)
+
+	assert.Nil(t, err)
+	assert.True(t, match.IsOver())
+	assert.Equal(t, len(results), len(match.Deals()))
+
+	var points [2]int
+	for i, result := range results {
+		assert.Equal(t, i%2, result.Tricks[0].Leader)
+		points[result.Winner] += result.GamePoints
+	}
+	assert.Equal(t, points[0], match.GetScore())
+	assert.Equal(t, points[1], match.GetOpponentScore())
+}
+
+// randomCardAgent plays a random valid card chosen with its own source.
+type randomCardAgent struct {
+	rng *rand.Rand
+}
+
+func (a randomCardAgent) GetMove(g *Game) Move {
+	hand := g.GetHand()
+	cardPlayed := g.GetCardPlayed()
+	if cardPlayed != nil && (g.IsClosed() || g.GetTrumpCard() == nil) {
+		hand = hand.GetValidResponses(*cardPlayed, g.GetTrump())
+	}
+	return Move{Card: hand.GetRandomCardFrom(a.rng)}
+}
+
+func TestTableSetRand(t *testing.T) {
+	play := func(seed int64) []DealResult {
+		table := NewTable(
+			randomCardAgent{rand.New(rand.NewSource(seed + 1))},
+			randomCardAgent{rand.New(rand.NewSource(seed + 2))},
+		)
+		table.SetRand(rand.New(rand.NewSource(seed)))
+
+		var results []DealResult
+		for i := 0; i < 5; i++ {
+			result, err := table.Play()
+			assert.Nil(t, err)
+			results = append(results, result)
+		}
+		return results
+	}
+
+	assert.Equal(t, play(1), play(1))
+	assert.NotEqual(t, play(1), play(2))
+}
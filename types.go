@@ -1,8 +1,8 @@
 package santase
 
 import (
+	"context"
 	"math/rand"
-	"sort"
 )
 
 // Suit represents one of the four suits a card can have.
@@ -106,7 +106,13 @@ var AllCards = []Card{
 }
 
 // Hand represents a collection of up to 6 different cards that a player may hold.
-type Hand map[Card]struct{}
+//
+// A Hand is a plain value backed by a CardSet: assigning it copies
+// the cards and the zero value is an empty hand. Use ToSlice to
+// iterate over the cards.
+type Hand struct {
+	cards CardSet
+}
 
 // NewHand initializes a new Hand.
 //
@@ -119,11 +125,7 @@ func NewHand(cards ...Card) Hand {
 		panic("too many cards given")
 	}
 
-	result := make(map[Card]struct{})
-	for _, card := range cards {
-		result[card] = struct{}{}
-	}
-	return result
+	return Hand{NewCardSet(cards...)}
 }
 
 // AddCard adds the passed card to the hand.
@@ -132,163 +134,128 @@ func NewHand(cards ...Card) Hand {
 //
 // If the hand has 6 cards already a panic will occur.
 func (h *Hand) AddCard(c Card) {
-	if len(*h) == 6 {
+	if h.Len() == 6 {
 		panic("hand has 6 cards already")
 	}
 
-	(*h)[c] = struct{}{}
+	h.cards.Add(c)
 }
 
 // HasCard checks if a card is in the hand.
 func (h *Hand) HasCard(c Card) bool {
-	_, ok := (*h)[c]
-	return ok
+	return h.cards.Has(c)
 }
 
 // RemoveCard removes a card from the hand.
 //
 // If the passed card is not in the hand this is a noop.
 func (h *Hand) RemoveCard(c Card) {
-	delete(*h, c)
+	h.cards.Remove(c)
+}
+
+// Len returns the number of cards in the hand.
+func (h *Hand) Len() int {
+	return h.cards.Len()
 }
 
 // Clone returns a (deep) copy of the hand.
 func (h *Hand) Clone() Hand {
-	hand := NewHand()
-	for card := range *h {
-		hand.AddCard(card)
-	}
-	return hand
+	return *h
 }
 
-// ToSlice converts the hand to a slice of cards.
+// ToSlice converts the hand to a slice of cards
+// sorted by suit and rank.
 func (h *Hand) ToSlice() []Card {
-	result := make([]Card, 0, len(*h))
-	for card := range *h {
-		result = append(result, card)
-	}
-	return result
+	return h.cards.Cards()
 }
 
 // GetValidResponses returns a new hand containing only the
 // cards that would be a valid response if the game is closed.
 func (h *Hand) GetValidResponses(played Card, trump Suit) Hand {
-	var allowedResponses []Card
+	sameSuit := h.cards.OfSuit(played.Suit)
 
-	for card := range *h {
-		if card.Suit == played.Suit && card.Rank > played.Rank {
-			allowedResponses = append(allowedResponses, card)
-		}
+	if stronger := sameSuit &^ (played.bit()<<1 - 1); stronger != 0 {
+		return Hand{stronger}
 	}
 
-	if allowedResponses == nil {
-		for card := range *h {
-			if card.Suit == played.Suit {
-				allowedResponses = append(allowedResponses, card)
-			}
-		}
+	if sameSuit != 0 {
+		return Hand{sameSuit}
 	}
 
-	if allowedResponses == nil && played.Suit != trump {
-		for card := range *h {
-			if card.Suit == trump {
-				allowedResponses = append(allowedResponses, card)
-			}
-		}
+	if trumps := h.cards.OfSuit(trump); trumps != 0 {
+		return Hand{trumps}
 	}
 
-	if allowedResponses == nil {
-		return h.Clone()
-	}
-
-	return NewHand(allowedResponses...)
+	return *h
 }
 
-// GetRandomCard returns a card chosen at random from the hand.
+// GetRandomCard returns a card chosen at random from the hand
+// using the global source of math/rand.
 func (h *Hand) GetRandomCard() Card {
-	i := rand.Intn(len(*h))
-	var card Card
-	for card = range *h {
-		if i == 0 {
-			break
-		}
-		i--
-	}
-	return card
+	return h.cards.Random(nil)
 }
 
-func (h Hand) String() string {
-	var cards []Card
-	for card := range h {
-		cards = append(cards, card)
-	}
-	sort.Slice(cards, func(i, j int) bool {
-		return cards[i].Suit < cards[j].Suit || (cards[i].Suit == cards[j].Suit && cards[i].Rank < cards[j].Rank)
-	})
+// GetRandomCardFrom returns a card chosen at random from the hand
+// using rng, so that the choice can be reproduced. If rng is nil
+// the global source of math/rand is used.
+func (h *Hand) GetRandomCardFrom(rng *rand.Rand) Card {
+	return h.cards.Random(rng)
+}
 
-	result := "{ "
-	for _, card := range cards {
-		result += card.String() + " "
-	}
-	result += "}"
-	return result
+func (h Hand) String() string {
+	return h.cards.String()
 }
 
 // Pile represents a collection of (different) cards.
 //
-// Unlike Hand there is no constraint on the number of cards in a Pile
-type Pile map[Card]struct{}
+// Unlike Hand there is no constraint on the number of cards in a Pile.
+// Like Hand it is backed by a CardSet and the zero value is an empty pile.
+type Pile struct {
+	cards CardSet
+}
 
 // NewPile initializes a new empty Pile.
 func NewPile() Pile {
-	return make(map[Card]struct{})
+	return Pile{}
 }
 
 // AddCard adds the passed card to the pile.
 //
 // If the card is already in the pile this is a noop.
 func (p *Pile) AddCard(c Card) {
-	(*p)[c] = struct{}{}
+	p.cards.Add(c)
 }
 
 // HasCard checks if a card is in the pile.
 func (p *Pile) HasCard(c Card) bool {
-	_, ok := (*p)[c]
-	return ok
+	return p.cards.Has(c)
 }
 
 // RemoveCard removes a card from the pile.
 //
 // If the passed card is not in the pile this is a noop.
 func (p *Pile) RemoveCard(c Card) {
-	delete(*p, c)
+	p.cards.Remove(c)
+}
+
+// Len returns the number of cards in the pile.
+func (p *Pile) Len() int {
+	return p.cards.Len()
 }
 
 // Clone returns a (deep) copy of the pile.
 func (p *Pile) Clone() Pile {
-	pile := NewPile()
-	for card := range *p {
-		pile.AddCard(card)
-	}
-	return pile
+	return *p
 }
 
-// ToSlice converts the pile to a slice of cards.
+// ToSlice converts the pile to a slice of cards
+// sorted by suit and rank.
 func (p *Pile) ToSlice() []Card {
-	result := make([]Card, 0, len(*p))
-	for card := range *p {
-		result = append(result, card)
-	}
-	return result
+	return p.cards.Cards()
 }
 
 func (p Pile) String() string {
-	result := "{ "
-	for card := range p {
-		result += card.String() + " "
-	}
-	result += "}"
-	return result
+	return p.cards.String()
 }
 
 // Move contains the information for a move that a player.
@@ -298,11 +265,23 @@ func (p Pile) String() string {
 // Additionally a move can be an announcement (if the player
 // has king and queen of matching suit), can switch the trump
 // card (if valid) and can close the game (if valid).
+//
+// Instead of playing a card, the player who is first to play can
+// declare that they have collected 66 points, which ends the game.
+// A declaration can be combined with an announcement, in which case
+// Card is the announced card and it is not played. Otherwise Card is
+// ignored. If the player turns out to have less than 66 points the
+// declaration loses the game.
+//
+// In JSON a move is an object with the card in text form (see
+// Card.MarshalText) and the flags that are set, e.g.
+// {"card":"QH","isAnnouncement":true}.
 type Move struct {
-	Card            Card
-	IsAnnouncement  bool
-	SwitchTrumpCard bool
-	CloseGame       bool
+	Card            Card `json:"card"`
+	IsAnnouncement  bool `json:"isAnnouncement,omitempty"`
+	SwitchTrumpCard bool `json:"switchTrumpCard,omitempty"`
+	CloseGame       bool `json:"closeGame,omitempty"`
+	Declare         bool `json:"declare,omitempty"`
 }
 
 // Agent represents a player in the game and is used to
@@ -322,3 +301,15 @@ type Move struct {
 type Agent interface {
 	GetMove(*Game) Move
 }
+
+// ContextAgent is an Agent whose search for a move can be cancelled.
+//
+// Game calls GetMoveContext instead of GetMove for agents that
+// implement it (see Game.GetMoveContext). When ctx is done the agent
+// should stop searching as soon as possible and return the best move
+// it has found so far. Plain GetMove is expected to behave like
+// GetMoveContext with context.Background().
+type ContextAgent interface {
+	Agent
+	GetMoveContext(ctx context.Context, g *Game) Move
+}
@@ -1,6 +1,7 @@
 package santase
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -114,3 +115,27 @@ func TestString(t *testing.T) {
 
 	assert.Equal(t, "{ 9♥ J♥ Q♥ K♥ 10♥ A♥ }", hand.String())
 }
+
+func TestGetRandomCardFrom(t *testing.T) {
+	hand := NewHand(
+		NewCard(Nine, Hearts),
+		NewCard(Jack, Hearts),
+		NewCard(Queen, Hearts),
+		NewCard(King, Hearts),
+		NewCard(Ten, Hearts),
+		NewCard(Ace, Hearts),
+	)
+
+	pick := func(seed int64) []Card {
+		rng := rand.New(rand.NewSource(seed))
+		var cards []Card
+		for i := 0; i < 10; i++ {
+			card := hand.GetRandomCardFrom(rng)
+			assert.True(t, hand.HasCard(card))
+			cards = append(cards, card)
+		}
+		return cards
+	}
+	assert.Equal(t, pick(1), pick(1))
+	assert.NotEqual(t, pick(1), pick(2))
+}
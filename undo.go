@@ -0,0 +1,32 @@
+package santase
+
+import "errors"
+
+// ErrNothingToUndo is returned by Undo when there is no action to undo.
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// Undo reverts the last successful call to GetMove, UpdateOpponentMove
+// or UpdateDrawnCard (or their Try* variants), restoring the game
+// exactly to the state it was in before the call. It can be called
+// repeatedly to go back to the start of the deal.
+//
+// If there is no action to undo ErrNothingToUndo is returned.
+func (g *Game) Undo() error {
+	if len(g.undo) == 0 {
+		return ErrNothingToUndo
+	}
+
+	state := g.undo[len(g.undo)-1]
+	agent, undo := g.agent, g.undo[:len(g.undo)-1]
+
+	*g = state.game()
+	g.agent = agent
+	g.undo = undo
+	return nil
+}
+
+// saveUndo saves the current state of the game,
+// so the next action can be undone.
+func (g *Game) saveUndo() {
+	g.undo = append(g.undo, g.Snapshot())
+}
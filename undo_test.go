@@ -0,0 +1,72 @@
+package santase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUndo(t *testing.T) {
+	game := createSampleGame()
+	assert.Equal(t, ErrNothingToUndo, game.Undo())
+
+	var states []GameState
+	save := func() {
+		states = append(states, game.Snapshot())
+	}
+
+	save()
+	game.UpdateOpponentMove(Move{Card: NewCard(Jack, Spades)})
+	save()
+	game.SetAgent(fixedAgent{Move{Card: NewCard(Ace, Spades)}})
+	game.GetMove()
+	save()
+	game.UpdateDrawnCard(NewCard(Jack, Hearts))
+	save()
+	game.SetAgent(fixedAgent{Move{Card: NewCard(Ten, Hearts)}})
+	game.GetMove()
+	save()
+	game.UpdateOpponentMove(Move{Card: NewCard(Ace, Hearts)})
+	game.UpdateDrawnCard(NewCard(King, Diamonds))
+	save()
+	game.UpdateOpponentMove(Move{Card: NewCard(Queen, Hearts), IsAnnouncement: true, SwitchTrumpCard: true})
+	assert.Equal(t, 41, game.GetOpponentScore())
+	assert.Equal(t, NewCard(Nine, Clubs), *game.GetTrumpCard())
+
+	// undo the switch and announcement
+	assert.Nil(t, game.Undo())
+	assert.Equal(t, states[5], game.Snapshot())
+	assert.Equal(t, NewCard(Ten, Clubs), *game.GetTrumpCard())
+	assert.Equal(t, 21, game.GetOpponentScore())
+	assert.False(t, game.knownOpponentCards.HasCard(NewCard(King, Hearts)))
+	assert.True(t, game.unseenCards.HasCard(NewCard(Nine, Clubs)))
+
+	// undo the draw and the trick
+	assert.Nil(t, game.Undo())
+	assert.Nil(t, game.Undo())
+	assert.Equal(t, states[4], game.Snapshot())
+
+	// the game can continue after undoing
+	game.UpdateOpponentMove(Move{Card: NewCard(Nine, Hearts)})
+	assert.Equal(t, 23, game.GetScore())
+
+	for game.Undo() == nil {
+	}
+	assert.Equal(t, states[0], game.Snapshot())
+	assert.Equal(t, fixedAgent{Move{Card: NewCard(Ten, Hearts)}}, game.agent)
+}
+
+func TestUndoFailedAction(t *testing.T) {
+	game := createSampleGame()
+	game.UpdateOpponentMove(Move{Card: NewCard(Jack, Spades)})
+	game.SetAgent(fixedAgent{Move{Card: NewCard(Ace, Spades)}})
+	game.GetMove()
+
+	// invalid actions cannot be undone, because they do not change the game
+	assert.Equal(t, ErrDrawnCardInHand, game.TryUpdateDrawnCard(NewCard(Nine, Spades)))
+	assert.Nil(t, game.Undo())
+	assert.Equal(t, NewCard(Jack, Spades), *game.GetCardPlayed())
+	assert.Nil(t, game.Undo())
+	assert.Nil(t, game.GetCardPlayed())
+	assert.Equal(t, ErrNothingToUndo, game.Undo())
+}
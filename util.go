@@ -57,3 +57,12 @@ func Points(c *Card) int {
 
 	panic("invalid card")
 }
+
+// marriagePartner returns the other card of the marriage (queen and
+// king of the same suit) the passed queen or king is part of.
+func marriagePartner(c Card) Card {
+	if c.Rank == Queen {
+		return NewCard(King, c.Suit)
+	}
+	return NewCard(Queen, c.Suit)
+}